@@ -0,0 +1,74 @@
+// Package cycle holds the cycle helpers of the 2024 module that this module
+// uses. Each year is a separate module that builds on its own, so the copy
+// is kept on purpose; fixes to one copy belong in the other.
+package cycle
+
+import "math/big"
+
+// Cycle describes a sequence x0, f(x0), f(f(x0)), ... that repeats after a
+// prefix of Start values with a period of Length values.
+type Cycle struct {
+	Start  int
+	Length int
+}
+
+func Brent[T comparable](x0 T, f func(T) T) Cycle {
+	return BrentFunc(x0, f, func(x, y T) bool { return x == y })
+}
+
+func BrentFunc[T any](x0 T, f func(T) T, equal func(T, T) bool) Cycle {
+	power, length := 1, 1
+	tortoise, hare := x0, f(x0)
+	for !equal(tortoise, hare) {
+		if power == length {
+			tortoise = hare
+			power *= 2
+			length = 0
+		}
+		hare = f(hare)
+		length++
+	}
+
+	tortoise, hare = x0, x0
+	for i := 0; i < length; i++ {
+		hare = f(hare)
+	}
+	start := 0
+	for !equal(tortoise, hare) {
+		tortoise, hare = f(tortoise), f(hare)
+		start++
+	}
+	return Cycle{start, length}
+}
+
+// CRT combines the congruences n ≡ r1 (mod m1) and n ≡ r2 (mod m2), which
+// need not have coprime moduli, into n ≡ r (mod m).
+func CRT(r1, m1, r2, m2 int) (int, int, bool) {
+	g := gcd(m1, m2)
+	if (r2-r1)%g != 0 {
+		return 0, 0, false
+	}
+	m := m1 / g * m2
+
+	bm1, bm2 := big.NewInt(int64(m1/g)), big.NewInt(int64(m2/g))
+	k := new(big.Int).ModInverse(bm1, bm2)
+	if k == nil {
+		k = big.NewInt(0)
+	}
+	k.Mul(k, big.NewInt(int64((r2-r1)/g)))
+	k.Mod(k, bm2)
+	r := new(big.Int).Mul(k, big.NewInt(int64(m1)))
+	r.Add(r, big.NewInt(int64(r1)))
+	r.Mod(r, big.NewInt(int64(m)))
+	return int(r.Int64()), m, true
+}
+
+func gcd(a, b int) int {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	if a < 0 {
+		return -a
+	}
+	return a
+}
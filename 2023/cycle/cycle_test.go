@@ -0,0 +1,49 @@
+package cycle
+
+import "testing"
+
+var cases = []struct {
+	next     []int
+	x0       int
+	expected Cycle
+}{
+	{[]int{0}, 0, Cycle{0, 1}},
+	{[]int{1, 2, 0}, 0, Cycle{0, 3}},
+	{[]int{1, 2, 3, 4, 5, 3}, 0, Cycle{3, 3}},
+	{[]int{1, 2, 3, 4, 5, 3}, 4, Cycle{0, 3}},
+	{[]int{1, 2, 2}, 0, Cycle{2, 1}},
+	{[]int{1, 2, 3, 4, 5, 6, 7, 8, 9, 1}, 0, Cycle{1, 9}},
+}
+
+func TestBrent(t *testing.T) {
+	for _, c := range cases {
+		result := Brent(c.x0, func(x int) int { return c.next[x] })
+		if result != c.expected {
+			t.Errorf("Brent(%d, %v) == %v, expected %v", c.x0, c.next, result, c.expected)
+		}
+	}
+}
+
+func TestCRT(t *testing.T) {
+	cases := []struct {
+		r1, m1, r2, m2 int
+		r, m           int
+		ok             bool
+	}{
+		{2, 3, 3, 5, 8, 15, true},
+		{0, 4, 2, 6, 8, 12, true},
+		{1, 4, 2, 6, 0, 0, false},
+		{0, 1, 5, 7, 5, 7, true},
+		{3, 101, 5, 103, 10305, 10403, true},
+		{7, 10000019, 11, 10000079, 93334247334740, 100000980001501, true},
+	}
+	for _, c := range cases {
+		r, m, ok := CRT(c.r1, c.m1, c.r2, c.m2)
+		if r != c.r || m != c.m || ok != c.ok {
+			t.Errorf(
+				"CRT(%d, %d, %d, %d) == %d, %d, %t, expected %d, %d, %t",
+				c.r1, c.m1, c.r2, c.m2, r, m, ok, c.r, c.m, c.ok,
+			)
+		}
+	}
+}
@@ -1,53 +1,35 @@
 package day1
 
-import (
-	"fmt"
-	"regexp"
-	"strings"
-)
+import "aoc2023/trie"
 
 type Digit struct {
-	Forward     string
-	Backward    string
+	Word        string
 	StringValue string
 	Value       int
 }
 
 type Digits []Digit
 
-func (digits Digits) ForwardPattern() string {
-	groups := make([]string, 0)
+func (digits Digits) Automaton() *trie.Automaton[int] {
+	t := trie.NewTrie[int]()
 	for _, digit := range digits {
-		groups = append(
-			groups,
-			fmt.Sprintf("(%s)", digit.StringValue),
-			fmt.Sprintf("(%s)", digit.Forward),
-		)
+		t.Insert(digit.StringValue, digit.Value)
+		t.Insert(digit.Word, digit.Value)
 	}
-	return strings.Join(groups, "|")
+	return t.Compile()
 }
 
-func (digits Digits) BackwardPattern() string {
-	groups := make([]string, 0)
-	for _, digit := range digits {
-		groups = append(
-			groups,
-			fmt.Sprintf("(%s)", digit.StringValue),
-			fmt.Sprintf("(%s)", digit.Backward),
-		)
-	}
-	return strings.Join(groups, "|")
-}
-
-func (digits Digits) Map() map[string]int {
-	result := make(map[string]int)
-	for _, digit := range digits {
-		result[digit.StringValue] = digit.Value
-		result[digit.Forward] = digit.Value
-		result[digit.Backward] = digit.Value
-	}
-	return result
-}
+var digitMatcher = Digits{
+	{"one", "1", 1},
+	{"two", "2", 2},
+	{"three", "3", 3},
+	{"four", "4", 4},
+	{"five", "5", 5},
+	{"six", "6", 6},
+	{"seven", "7", 7},
+	{"eight", "8", 8},
+	{"nine", "9", 9},
+}.Automaton()
 
 func Reverse(s string) string {
 	runes := []rune(s)
@@ -59,30 +41,18 @@ func Reverse(s string) string {
 }
 
 func FindValue(input string) int {
-	if len(input) == 0 {
-		return 0
-	}
-
-	digits := Digits{
-		{"one", "eno", "1", 1},
-		{"two", "owt", "2", 2},
-		{"three", "eerht", "3", 3},
-		{"four", "ruof", "4", 4},
-		{"five", "evif", "5", 5},
-		{"six", "xis", "6", 6},
-		{"seven", "neves", "7", 7},
-		{"eight", "thgie", "8", 8},
-		{"nine", "enin", "9", 9},
+	var first, last trie.Match[int]
+	found := false
+	for _, match := range digitMatcher.FindAll(input) {
+		if !found || match.Start < first.Start {
+			first = match
+		}
+		if !found || match.End > last.End {
+			last = match
+		}
+		found = true
 	}
-
-	validDigit := regexp.MustCompile(digits.ForwardPattern())
-	validReverseDigit := regexp.MustCompile(digits.BackwardPattern())
-
-	digitMap := digits.Map()
-	firstDigit := digitMap[validDigit.FindString(input)]
-	lastDigit := digitMap[validReverseDigit.FindString(Reverse(input))]
-
-	return firstDigit*10 + lastDigit
+	return first.Value*10 + last.Value
 }
 
 func Sum(inputs []string) int {
@@ -1,19 +1,17 @@
 package day8
 
 import (
+	"aoc2023/cycle"
 	"fmt"
-	"log"
 	"regexp"
 	"strings"
 )
 
 const (
-	left     = 'L'
-	right    = 'R'
-	start    = "AAA"
-	end      = "ZZZ"
-	maxInt   = int(^uint(0) >> 1)
-	maxSteps = 1_000_000
+	left  = 'L'
+	right = 'R'
+	start = "AAA"
+	end   = "ZZZ"
 )
 
 var (
@@ -88,106 +86,20 @@ func (g Graph) Equals(other Graph) bool {
 	return true
 }
 
-type Cycle struct {
-	Start  int
-	Length int
+type ghostState struct {
+	node           string
+	directionIndex int
 }
 
-var NonCycle = Cycle{-1, -1}
-
-type Set map[int]bool
-
-func (s Set) Intersection(other Set) Set {
-	result := make(Set)
-	for k := range s {
-		if other[k] {
-			result[k] = true
+func (g Graph) nextState(directions Directions) func(ghostState) ghostState {
+	return func(s ghostState) ghostState {
+		node := g.Nodes[s.node]
+		next := node.Right
+		if directions[s.directionIndex] == left {
+			next = node.Left
 		}
+		return ghostState{next, (s.directionIndex + 1) % len(directions)}
 	}
-	return result
-}
-
-type NodeIndexPair struct {
-	Node           Node
-	DirectionIndex int
-}
-
-type Ghost struct {
-	Path           []Node
-	PathMap        map[NodeIndexPair]int
-	Directions     *Directions
-	Graph          *Graph
-	Start          string
-	EndIndices     []int
-	EndIndexSet    Set
-	Node           Node
-	DirectionIndex int
-	PathIndex      int
-	Cycle          Cycle
-}
-
-func NewGhost(directions *Directions, graph *Graph, start string) Ghost {
-	return Ghost{
-		Path:           []Node{graph.Nodes[start]},
-		PathMap:        map[NodeIndexPair]int{{graph.Nodes[start], 0}: 0},
-		Graph:          graph,
-		Directions:     directions,
-		Start:          start,
-		EndIndices:     []int{},
-		EndIndexSet:    Set{},
-		Node:           graph.Nodes[start],
-		DirectionIndex: 0,
-		PathIndex:      0,
-		Cycle:          NonCycle,
-	}
-}
-func (g *Ghost) Step() {
-	if g.Cycle != NonCycle {
-		g.DirectionIndex = (g.DirectionIndex + 1) % len(*g.Directions)
-		g.PathIndex = g.Cycle.Start + (g.PathIndex+1-g.Cycle.Start)%g.Cycle.Length
-		g.Node = g.Path[g.PathIndex]
-		return
-	}
-	direction := (*g.Directions)[g.DirectionIndex]
-	if direction == left {
-		g.Node = g.Graph.Nodes[g.Node.Left]
-	} else {
-		g.Node = g.Graph.Nodes[g.Node.Right]
-	}
-	if g.Graph.Ends[g.Node.Id] {
-		g.EndIndices = append(g.EndIndices, len(g.Path))
-		g.EndIndexSet[len(g.Path)] = true
-	}
-	prev, ok := g.PathMap[NodeIndexPair{g.Node, g.DirectionIndex}]
-	if ok && len(g.EndIndices) != 0 {
-		g.Cycle = Cycle{prev, len(g.Path) - prev}
-	} else {
-		g.PathMap[NodeIndexPair{g.Node, g.DirectionIndex}] = len(g.Path)
-		g.Path = append(g.Path, g.Node)
-	}
-	g.DirectionIndex = (g.DirectionIndex + 1) % len(*g.Directions)
-	g.PathIndex++
-}
-
-func (g *Ghost) IsEndStep(step int) bool {
-	return g.Cycle.Start+(step-g.Cycle.Start)%g.Cycle.Length == g.EndIndices[0]
-}
-
-func (g *Ghost) GenerateEndStep() {
-	endStep := g.EndIndices[len(g.EndIndices)-1] + g.Cycle.Length
-	g.EndIndices = append(g.EndIndices, endStep)
-	g.EndIndexSet[endStep] = true
-}
-
-func GCDEuclid(a, b int) int {
-	if a == 0 {
-		return b
-	}
-	return GCDEuclid(b%a, a)
-}
-
-func LCM(a, b int) int {
-	return a * b / GCDEuclid(a, b)
 }
 
 func CountSteps(lines []string) int {
@@ -217,85 +129,70 @@ func CountSteps(lines []string) int {
 }
 
 func CountParallelSteps(lines []string) int {
-	log.Printf("Running for at most %d steps\n", maxSteps)
 	directions, err := NewDirections(lines[0])
-	lenDirections := len(directions)
 	if err != nil {
 		return -1
 	}
 	graph := NewGraph(lines[1:])
-	numGhosts := len(graph.Starts)
-	ghosts := make([]Ghost, 0, numGhosts)
+	next := graph.nextState(directions)
 
+	states := make([]ghostState, 0, len(graph.Starts))
+	cycles := make([]cycle.Cycle, 0, len(graph.Starts))
+	prefix := 0
 	for start := range graph.Starts {
-		ghost := NewGhost(&directions, &graph, start)
-		ghosts = append(ghosts, ghost)
+		state := ghostState{start, 0}
+		c := cycle.Brent(state, next)
+		states = append(states, state)
+		cycles = append(cycles, c)
+		prefix = max(prefix, c.Start)
 	}
-	index := 0
-	steps := 0
-	for {
-		for i := 0; i < numGhosts; i++ {
-			ghosts[i].Step()
-		}
-		if steps == maxSteps {
-			return -1
-		}
-		steps++
-		done := true
-		for _, ghost := range ghosts {
-			if !graph.Ends[ghost.Node.Id] {
-				done = false
-				break
+
+	allEnds := func() bool {
+		for _, s := range states {
+			if !graph.Ends[s.node] {
+				return false
 			}
 		}
-		if done {
-			break
+		return true
+	}
+	for steps := 0; steps < prefix; steps++ {
+		if allEnds() {
+			return steps
 		}
-		allCycling := true
-		for _, ghost := range ghosts {
-			if ghost.Cycle == NonCycle {
-				allCycling = false
-				break
-			}
+		for i := range states {
+			states[i] = next(states[i])
 		}
-		if allCycling {
+	}
 
-			/*
-				fmt.Printf("All ghosts are cycling after %d steps\n", steps)
-				for n := 0; n < 10; n++ {
-					commonEndIndices := ghosts[0].EndIndexSet
-					for i := 1; i < numGhosts; i++ {
-						commonEndIndices = commonEndIndices.Intersection(ghosts[i].EndIndexSet)
+	// Every ghost is now inside its cycle, so it is at an end exactly on the
+	// steps congruent to one of the end offsets within the cycle.
+	candidates := [][2]int{{0, 1}}
+	for i, c := range cycles {
+		combined := [][2]int{}
+		state := states[i]
+		for offset := 0; offset < c.Length; offset++ {
+			if graph.Ends[state.node] {
+				for _, candidate := range candidates {
+					r, m, ok := cycle.CRT(candidate[0], candidate[1], (prefix+offset)%c.Length, c.Length)
+					if ok {
+						combined = append(combined, [2]int{r, m})
 					}
-					if len(commonEndIndices) == 0 {
-						for i := 0; i < numGhosts; i++ {
-							ghosts[i].GenerateEndStep()
-						}
-					} else {
-						endStep := maxInt
-						for endIndex := range commonEndIndices {
-							if endIndex < endStep {
-								endStep = endIndex
-							}
-						}
-						return endStep
-					}
-				}
-				for i := 0; i < numGhosts; i++ {
-					ghost := ghosts[i]
-					fmt.Printf("%#v, EndIndices: %v\n", ghost.Cycle, ghost.EndIndices)
 				}
-				return -1
-			*/
-			lcm := 1
-			for i := 0; i < numGhosts; i++ {
-				ghost := ghosts[i]
-				lcm = LCM(lcm, ghost.Cycle.Length)
-				fmt.Printf("%#v, EndIndex: %v\n", ghost.Cycle, ghost.EndIndices)
 			}
-			return lcm
+			state = next(state)
+		}
+		candidates = combined
+	}
+
+	steps := -1
+	for _, candidate := range candidates {
+		r, m := candidate[0], candidate[1]
+		if r < prefix {
+			r += (prefix - r + m - 1) / m * m
+		}
+		if steps < 0 || r < steps {
+			steps = r
 		}
-		index = (index + 1) % lenDirections
 	}
 	return steps
 }
@@ -0,0 +1,126 @@
+// Package trie holds the Aho-Corasick matcher of the 2024 module without
+// iterators, which this module's Go version lacks. Each year is a separate
+// module that builds on its own, so the copy is kept on purpose; fixes to
+// one copy belong in the other.
+package trie
+
+import "maps"
+
+type node[V any] struct {
+	children map[byte]int
+	value    V
+	terminal bool
+	depth    int
+}
+
+type Trie[V any] struct {
+	nodes []node[V]
+	size  int
+}
+
+func NewTrie[V any]() *Trie[V] {
+	return &Trie[V]{[]node[V]{{children: map[byte]int{}}}, 0}
+}
+
+func (t *Trie[V]) Insert(key string, value V) {
+	current := 0
+	for i := 0; i < len(key); i++ {
+		next, ok := t.nodes[current].children[key[i]]
+		if !ok {
+			next = len(t.nodes)
+			t.nodes = append(t.nodes, node[V]{children: map[byte]int{}, depth: i + 1})
+			t.nodes[current].children[key[i]] = next
+		}
+		current = next
+	}
+	if !t.nodes[current].terminal {
+		t.size++
+	}
+	t.nodes[current].value = value
+	t.nodes[current].terminal = true
+}
+
+type Match[V any] struct {
+	Start, End int
+	Value      V
+}
+
+// Automaton is an Aho-Corasick automaton finding every occurrence of the
+// keys of a trie in a single pass over the input.
+type Automaton[V any] struct {
+	nodes  []node[V]
+	fail   []int
+	output []int
+}
+
+func (t *Trie[V]) Compile() *Automaton[V] {
+	nodes := make([]node[V], len(t.nodes))
+	for i, n := range t.nodes {
+		nodes[i] = n
+		nodes[i].children = maps.Clone(n.children)
+	}
+	a := &Automaton[V]{nodes, make([]int, len(nodes)), make([]int, len(nodes))}
+	a.output[0] = -1
+
+	queue := []int{}
+	for _, child := range nodes[0].children {
+		a.fail[child] = 0
+		a.output[child] = -1
+		queue = append(queue, child)
+	}
+	for len(queue) > 0 {
+		n := queue[0]
+		queue = queue[1:]
+		for b, child := range nodes[n].children {
+			f := a.fail[n]
+			for {
+				if next, ok := nodes[f].children[b]; ok {
+					f = next
+					break
+				}
+				if f == 0 {
+					break
+				}
+				f = a.fail[f]
+			}
+			a.fail[child] = f
+			if nodes[f].terminal {
+				a.output[child] = f
+			} else {
+				a.output[child] = a.output[f]
+			}
+			queue = append(queue, child)
+		}
+	}
+	return a
+}
+
+func (a *Automaton[V]) next(state int, b byte) int {
+	for {
+		if next, ok := a.nodes[state].children[b]; ok {
+			return next
+		}
+		if state == 0 {
+			return 0
+		}
+		state = a.fail[state]
+	}
+}
+
+// FindAll returns every, possibly overlapping, match in s ordered by end
+// position, longest first for matches ending at the same position.
+func (a *Automaton[V]) FindAll(s string) []Match[V] {
+	matches := []Match[V]{}
+	state := 0
+	for i := 0; i < len(s); i++ {
+		state = a.next(state, s[i])
+		n := state
+		if !a.nodes[n].terminal {
+			n = a.output[n]
+		}
+		for ; n >= 0; n = a.output[n] {
+			matches = append(matches, Match[V]{i + 1 - a.nodes[n].depth, i + 1, a.nodes[n].value})
+		}
+	}
+	return matches
+}
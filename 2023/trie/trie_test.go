@@ -0,0 +1,56 @@
+package trie
+
+import (
+	"slices"
+	"testing"
+)
+
+func newTestTrie(keys ...string) *Trie[int] {
+	t := NewTrie[int]()
+	for i, key := range keys {
+		t.Insert(key, i)
+	}
+	return t
+}
+
+func TestFindAll(t *testing.T) {
+	cases := []struct {
+		keys     []string
+		s        string
+		expected []Match[int]
+	}{
+		{
+			[]string{"he", "she", "his", "hers"},
+			"ushers",
+			[]Match[int]{{1, 4, 1}, {2, 4, 0}, {2, 6, 3}},
+		},
+		{
+			[]string{"one", "two", "eight", "1"},
+			"xtwone1eightwo",
+			[]Match[int]{{1, 4, 1}, {3, 6, 0}, {6, 7, 3}, {7, 12, 2}, {11, 14, 1}},
+		},
+		{
+			[]string{"a", "aa", "aaa"},
+			"aaa",
+			[]Match[int]{{0, 1, 0}, {0, 2, 1}, {1, 2, 0}, {0, 3, 2}, {1, 3, 1}, {2, 3, 0}},
+		},
+		{
+			[]string{"abc"},
+			"ababd",
+			[]Match[int]{},
+		},
+	}
+	for _, c := range cases {
+		a := newTestTrie(c.keys...).Compile()
+		result := a.FindAll(c.s)
+		slices.SortStableFunc(result, func(x, y Match[int]) int {
+			if x.End != y.End {
+				return x.End - y.End
+			}
+			return x.Start - y.Start
+		})
+		if !slices.Equal(result, c.expected) {
+			t.Errorf("FindAll(%v, %q) == %v, expected %v", c.keys, c.s, result, c.expected)
+		}
+	}
+}
@@ -33,6 +33,7 @@ import (
 	"os"
 	"runtime"
 	"runtime/pprof"
+	"strconv"
 	"strings"
 )
 
@@ -41,6 +42,11 @@ func main() {
 	partFlag := flag.Int("p", 1, "Part to run")
 	cpuprofile := flag.String("cpuprofile", "", "write cpu profile to file")
 	memprofile := flag.String("memprofile", "", "write memory profile to file")
+	memoCap := flag.Int("memocap", 0, "maximum entries kept by memoized solvers, 0 for unbounded")
+	memoStats := flag.Bool("memostats", false, "log cache statistics of memoized solvers")
+	bigInt := flag.Bool("bigint", false, "run the day 17 program with arbitrary-precision registers")
+	strategy := flag.String("strategy", "", "compact the day 9 disk with the named strategy and report on it")
+	export := flag.String("export", "", "write the day 24 circuit as dot or verilog instead of solving")
 
 	flag.Parse()
 
@@ -72,6 +78,47 @@ func main() {
 
 	writer := bufio.NewWriter(os.Stdout)
 
+	if *export != "" {
+		var err error
+		switch [2]any{day, *export} {
+		case [2]any{24, "dot"}:
+			err = day24.ExportDOT(inputLines, writer)
+		case [2]any{24, "verilog"}:
+			err = day24.ExportVerilog(inputLines, writer)
+		default:
+			log.Fatal("Invalid day or export format")
+		}
+		if err != nil {
+			log.Fatal("Could not export circuit: ", err)
+		}
+		writer.Flush()
+		return
+	}
+
+	if *strategy != "" {
+		if day != 9 {
+			log.Fatal("Strategies are only available for day 9")
+		}
+		report, err := day9.CompactWith(inputLines, *strategy)
+		if err != nil {
+			log.Fatal(err)
+		}
+		writer.WriteString(fmt.Sprintln(report))
+		writer.Flush()
+		return
+	}
+
+	if flag.NArg() > 0 {
+		if day != 17 {
+			log.Fatal("Subcommands are only available for day 17")
+		}
+		if err := runDay17Command(flag.Args(), inputLines, writer); err != nil {
+			log.Fatal(err)
+		}
+		writer.Flush()
+		return
+	}
+
 	switch [2]int{day, part} {
 	case [2]int{1, 1}:
 		writer.WriteString(fmt.Sprintln(day1.SumDistances(inputLines)))
@@ -114,9 +161,17 @@ func main() {
 	case [2]int{10, 2}:
 		writer.WriteString(fmt.Sprintln(day10.SumTrailRatings(inputLines)))
 	case [2]int{11, 1}:
-		writer.WriteString(fmt.Sprintln(day11.CountPebbles(inputLines, 25)))
+		count, stats := day11.CountPebblesCached(inputLines, 25, *memoCap)
+		writer.WriteString(fmt.Sprintln(count))
+		if *memoStats {
+			log.Printf("Memo %v", stats)
+		}
 	case [2]int{11, 2}:
-		writer.WriteString(fmt.Sprintln(day11.CountPebbles(inputLines, 75)))
+		count, stats := day11.CountPebblesCached(inputLines, 75, *memoCap)
+		writer.WriteString(fmt.Sprintln(count))
+		if *memoStats {
+			log.Printf("Memo %v", stats)
+		}
 	case [2]int{12, 1}:
 		writer.WriteString(fmt.Sprintln(day12.SumFencePrice(inputLines)))
 	case [2]int{12, 2}:
@@ -128,7 +183,9 @@ func main() {
 	case [2]int{14, 1}:
 		writer.WriteString(fmt.Sprintln(day14.CalcSafetyFactor(inputLines, 103, 101, 100)))
 	case [2]int{14, 2}:
-		writer.WriteString(fmt.Sprintln(day14.FindSignal(inputLines, 103, 101)))
+		signal := day14.FindSignal(inputLines, 103, 101)
+		writer.WriteString(fmt.Sprintln(signal.Frame))
+		writer.WriteString(fmt.Sprintln(signal.Step))
 	case [2]int{15, 1}:
 		writer.WriteString(fmt.Sprintln(day15.SumCoordinates(inputLines)))
 	case [2]int{15, 2}:
@@ -138,7 +195,11 @@ func main() {
 	case [2]int{16, 2}:
 		writer.WriteString(fmt.Sprintln(day16.CountTiles(inputLines)))
 	case [2]int{17, 1}:
-		writer.WriteString(fmt.Sprintln(day17.ExecProgram(inputLines)))
+		if *bigInt {
+			writer.WriteString(fmt.Sprintln(day17.ExecProgramBig(inputLines)))
+		} else {
+			writer.WriteString(fmt.Sprintln(day17.ExecProgram(inputLines)))
+		}
 	case [2]int{17, 2}:
 		writer.WriteString(fmt.Sprintln(day17.FindRegisterAValue(inputLines)))
 	case [2]int{18, 1}:
@@ -146,9 +207,17 @@ func main() {
 	case [2]int{18, 2}:
 		writer.WriteString(fmt.Sprintln(day18.FindFinalInput(inputLines, 71, 71)))
 	case [2]int{19, 1}:
-		writer.WriteString(fmt.Sprintln(day19.CountPossible(inputLines)))
+		count, stats := day19.CountPossibleCached(inputLines, *memoCap)
+		writer.WriteString(fmt.Sprintln(count))
+		if *memoStats {
+			log.Printf("Memo %v", stats)
+		}
 	case [2]int{19, 2}:
-		writer.WriteString(fmt.Sprintln(day19.SumCombinations(inputLines)))
+		sum, stats := day19.SumCombinationsCached(inputLines, *memoCap)
+		writer.WriteString(fmt.Sprintln(sum))
+		if *memoStats {
+			log.Printf("Memo %v", stats)
+		}
 	case [2]int{20, 1}:
 		writer.WriteString(fmt.Sprintln(day20.CountCheats(inputLines, 2, 100)))
 	case [2]int{20, 2}:
@@ -168,7 +237,11 @@ func main() {
 	case [2]int{24, 1}:
 		writer.WriteString(fmt.Sprintln(day24.Evaluate(inputLines)))
 	case [2]int{24, 2}:
-		writer.WriteString(fmt.Sprintln(day24.FindSwapped(inputLines)))
+		swapped, err := day24.FindSwapped(inputLines)
+		if err != nil {
+			log.Fatal(err)
+		}
+		writer.WriteString(fmt.Sprintln(swapped))
 	case [2]int{25, 1}:
 		writer.WriteString(fmt.Sprintln(day25.CountFits(inputLines)))
 	default:
@@ -191,3 +264,43 @@ func main() {
 		}
 	}
 }
+
+// runDay17Command handles the disasm, asm and trace subcommands, which
+// inspect the day 17 program read from stdin instead of solving a part.
+func runDay17Command(args []string, inputLines []string, writer *bufio.Writer) error {
+	switch args[0] {
+	case "disasm":
+		writer.WriteString(fmt.Sprintln(day17.Disassemble(inputLines)))
+	case "asm":
+		program, err := day17.Assemble(inputLines)
+		if err != nil {
+			return err
+		}
+		writer.WriteString(fmt.Sprintln(program))
+	case "trace":
+		traceFlags := flag.NewFlagSet("trace", flag.ExitOnError)
+		maxSteps := traceFlags.Int("steps", 10000, "maximum number of instructions to trace")
+		breakpoints := traceFlags.String("break", "", "comma separated addresses to stop before")
+		traceFlags.Parse(args[1:])
+		tracer := day17.NewTracer(inputLines)
+		if *breakpoints != "" {
+			for _, field := range strings.Split(*breakpoints, ",") {
+				address, err := strconv.Atoi(field)
+				if err != nil {
+					return fmt.Errorf("invalid breakpoint %q", field)
+				}
+				tracer.SetBreakpoint(address)
+			}
+		}
+		stopped := tracer.Run(*maxSteps)
+		for _, step := range tracer.Steps {
+			writer.WriteString(fmt.Sprintln(step))
+		}
+		if stopped {
+			writer.WriteString(fmt.Sprintf("breakpoint at %02d\n", tracer.Address()))
+		}
+	default:
+		return fmt.Errorf("unknown subcommand %q", args[0])
+	}
+	return nil
+}
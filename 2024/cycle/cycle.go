@@ -0,0 +1,141 @@
+package cycle
+
+import "math/big"
+
+// Cycle describes a sequence x0, f(x0), f(f(x0)), ... that repeats after a
+// prefix of Start values with a period of Length values.
+type Cycle struct {
+	Start  int
+	Length int
+}
+
+// Index maps step n of the sequence to the earliest step with the same value.
+func (c Cycle) Index(n int) int {
+	if n < c.Start {
+		return n
+	}
+	return c.Start + (n-c.Start)%c.Length
+}
+
+func Floyd[T comparable](x0 T, f func(T) T) Cycle {
+	return FloydFunc(x0, f, func(x, y T) bool { return x == y })
+}
+
+func FloydFunc[T any](x0 T, f func(T) T, equal func(T, T) bool) Cycle {
+	tortoise, hare := f(x0), f(f(x0))
+	for !equal(tortoise, hare) {
+		tortoise, hare = f(tortoise), f(f(hare))
+	}
+
+	start := 0
+	tortoise = x0
+	for !equal(tortoise, hare) {
+		tortoise, hare = f(tortoise), f(hare)
+		start++
+	}
+
+	length := 1
+	hare = f(tortoise)
+	for !equal(tortoise, hare) {
+		hare = f(hare)
+		length++
+	}
+	return Cycle{start, length}
+}
+
+func Brent[T comparable](x0 T, f func(T) T) Cycle {
+	return BrentFunc(x0, f, func(x, y T) bool { return x == y })
+}
+
+func BrentFunc[T any](x0 T, f func(T) T, equal func(T, T) bool) Cycle {
+	power, length := 1, 1
+	tortoise, hare := x0, f(x0)
+	for !equal(tortoise, hare) {
+		if power == length {
+			tortoise = hare
+			power *= 2
+			length = 0
+		}
+		hare = f(hare)
+		length++
+	}
+
+	tortoise, hare = x0, x0
+	for range length {
+		hare = f(hare)
+	}
+	start := 0
+	for !equal(tortoise, hare) {
+		tortoise, hare = f(tortoise), f(hare)
+		start++
+	}
+	return Cycle{start, length}
+}
+
+// History detects cycles by remembering the step at which each state, or a
+// hash of it, was first seen. Unlike Floyd and Brent it does not need to be
+// able to restart the sequence, so it suits simulations that mutate state.
+type History[K comparable] struct {
+	seen  map[K]int
+	steps int
+}
+
+func NewHistory[K comparable]() *History[K] {
+	return &History[K]{map[K]int{}, 0}
+}
+
+// Add records the state for the next step and returns the cycle it closes,
+// if the state has been seen before.
+func (h *History[K]) Add(key K) (Cycle, bool) {
+	if prev, ok := h.seen[key]; ok {
+		return Cycle{prev, h.steps - prev}, true
+	}
+	h.seen[key] = h.steps
+	h.steps++
+	return Cycle{}, false
+}
+
+func (h *History[K]) Len() int {
+	return h.steps
+}
+
+func Detect[T any, K comparable](x0 T, f func(T) T, key func(T) K) Cycle {
+	h := NewHistory[K]()
+	for x := x0; ; x = f(x) {
+		if c, ok := h.Add(key(x)); ok {
+			return c
+		}
+	}
+}
+
+// CRT combines the congruences n ≡ r1 (mod m1) and n ≡ r2 (mod m2), which
+// need not have coprime moduli, into n ≡ r (mod m).
+func CRT(r1, m1, r2, m2 int) (int, int, bool) {
+	g := gcd(m1, m2)
+	if (r2-r1)%g != 0 {
+		return 0, 0, false
+	}
+	m := m1 / g * m2
+
+	bm1, bm2 := big.NewInt(int64(m1/g)), big.NewInt(int64(m2/g))
+	k := new(big.Int).ModInverse(bm1, bm2)
+	if k == nil {
+		k = big.NewInt(0)
+	}
+	k.Mul(k, big.NewInt(int64((r2-r1)/g)))
+	k.Mod(k, bm2)
+	r := new(big.Int).Mul(k, big.NewInt(int64(m1)))
+	r.Add(r, big.NewInt(int64(r1)))
+	r.Mod(r, big.NewInt(int64(m)))
+	return int(r.Int64()), m, true
+}
+
+func gcd(a, b int) int {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	if a < 0 {
+		return -a
+	}
+	return a
+}
@@ -0,0 +1,114 @@
+package cycle
+
+import (
+	"fmt"
+	"testing"
+)
+
+var cases = []struct {
+	next     []int
+	x0       int
+	expected Cycle
+}{
+	{[]int{0}, 0, Cycle{0, 1}},
+	{[]int{1, 2, 0}, 0, Cycle{0, 3}},
+	{[]int{1, 2, 3, 4, 5, 3}, 0, Cycle{3, 3}},
+	{[]int{1, 2, 3, 4, 5, 3}, 4, Cycle{0, 3}},
+	{[]int{1, 2, 2}, 0, Cycle{2, 1}},
+	{[]int{1, 2, 3, 4, 5, 6, 7, 8, 9, 1}, 0, Cycle{1, 9}},
+}
+
+func TestFloyd(t *testing.T) {
+	for _, c := range cases {
+		result := Floyd(c.x0, func(x int) int { return c.next[x] })
+		if result != c.expected {
+			t.Errorf("Floyd(%d, %v) == %v, expected %v", c.x0, c.next, result, c.expected)
+		}
+	}
+}
+
+func TestBrent(t *testing.T) {
+	for _, c := range cases {
+		result := Brent(c.x0, func(x int) int { return c.next[x] })
+		if result != c.expected {
+			t.Errorf("Brent(%d, %v) == %v, expected %v", c.x0, c.next, result, c.expected)
+		}
+	}
+}
+
+func TestDetect(t *testing.T) {
+	for _, c := range cases {
+		result := Detect(c.x0, func(x int) int { return c.next[x] }, func(x int) string { return fmt.Sprint(x) })
+		if result != c.expected {
+			t.Errorf("Detect(%d, %v) == %v, expected %v", c.x0, c.next, result, c.expected)
+		}
+	}
+}
+
+func TestBrentFunc(t *testing.T) {
+	f := func(x []int) []int {
+		return []int{x[1], (x[0] + x[1]) % 3}
+	}
+	equal := func(x, y []int) bool {
+		return x[0] == y[0] && x[1] == y[1]
+	}
+	expected := Cycle{0, 8}
+	result := BrentFunc([]int{0, 1}, f, equal)
+	if result != expected {
+		t.Errorf("BrentFunc(fibonacci mod 3) == %v, expected %v", result, expected)
+	}
+	result = FloydFunc([]int{0, 1}, f, equal)
+	if result != expected {
+		t.Errorf("FloydFunc(fibonacci mod 3) == %v, expected %v", result, expected)
+	}
+}
+
+func TestHistory(t *testing.T) {
+	h := NewHistory[byte]()
+	for i, b := range []byte("abcdc") {
+		c, ok := h.Add(b)
+		if i < 4 && ok {
+			t.Errorf("Add(%q) == %v, %t at step %d, expected no cycle", b, c, ok, i)
+		}
+		if i == 4 && (!ok || c != Cycle{2, 2}) {
+			t.Errorf("Add(%q) == %v, %t, expected %v, true", b, c, ok, Cycle{2, 2})
+		}
+	}
+	if h.Len() != 4 {
+		t.Errorf("Len() == %d, expected 4", h.Len())
+	}
+}
+
+func TestIndex(t *testing.T) {
+	c := Cycle{3, 4}
+	for n, expected := range []int{0, 1, 2, 3, 4, 5, 6, 3, 4, 5, 6, 3} {
+		result := c.Index(n)
+		if result != expected {
+			t.Errorf("Index(%d) == %d, expected %d", n, result, expected)
+		}
+	}
+}
+
+func TestCRT(t *testing.T) {
+	cases := []struct {
+		r1, m1, r2, m2 int
+		r, m           int
+		ok             bool
+	}{
+		{2, 3, 3, 5, 8, 15, true},
+		{0, 4, 2, 6, 8, 12, true},
+		{1, 4, 2, 6, 0, 0, false},
+		{0, 1, 5, 7, 5, 7, true},
+		{3, 101, 5, 103, 10305, 10403, true},
+		{7, 10000019, 11, 10000079, 93334247334740, 100000980001501, true},
+	}
+	for _, c := range cases {
+		r, m, ok := CRT(c.r1, c.m1, c.r2, c.m2)
+		if r != c.r || m != c.m || ok != c.ok {
+			t.Errorf(
+				"CRT(%d, %d, %d, %d) == %d, %d, %t, expected %d, %d, %t",
+				c.r1, c.m1, c.r2, c.m2, r, m, ok, c.r, c.m, c.ok,
+			)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package day11
 
 import (
+	"aoc2024/memo"
 	"log"
 	"slices"
 	"strconv"
@@ -28,19 +29,16 @@ func Join(digits []int) int {
 }
 
 type Counter struct {
-	memo map[[2]int]int
+	cache *memo.Func[memo.Pair[int, int], int]
 }
 
-func NewCounter() *Counter {
-	return &Counter{memo: map[[2]int]int{}}
+func NewCounter(capacity int) *Counter {
+	return &Counter{memo.NewLRU(capacity, countAncestors)}
 }
 
-func (c *Counter) CountAncestors(pebble int, nBlinks int) int {
-	if count, ok := c.memo[[2]int{pebble, nBlinks}]; ok {
-		return count
-	}
+func countAncestors(count func(memo.Pair[int, int]) int, key memo.Pair[int, int]) int {
+	pebble, nBlinks := key.First, key.Second
 	if nBlinks == 0 {
-		c.memo[[2]int{pebble, 0}] = 1
 		return 1
 	}
 	digits := Split(pebble)
@@ -54,18 +52,33 @@ func (c *Counter) CountAncestors(pebble int, nBlinks int) int {
 	default:
 		newPebbles = append(newPebbles, pebble*2024)
 	}
-	count := 0
+	total := 0
 	for _, p := range newPebbles {
-		count += c.CountAncestors(p, nBlinks-1)
+		total += count(memo.Pair[int, int]{First: p, Second: nBlinks - 1})
 	}
-	c.memo[[2]int{pebble, nBlinks}] = count
-	return count
+	return total
+}
+
+func (c *Counter) CountAncestors(pebble int, nBlinks int) int {
+	return c.cache.Call(memo.Pair[int, int]{First: pebble, Second: nBlinks})
+}
+
+func (c *Counter) Stats() memo.Stats {
+	return c.cache.Stats()
 }
 
 func CountPebbles(inputs []string, nBlinks int) int {
+	count, _ := CountPebblesCached(inputs, nBlinks, 0)
+	return count
+}
+
+// CountPebblesCached counts the pebbles after nBlinks blinks, remembering
+// how many each (pebble, blinks left) pair becomes. Only capacity pairs are
+// kept when capacity is positive, which trades repeated work for memory.
+func CountPebblesCached(inputs []string, nBlinks int, capacity int) (int, memo.Stats) {
 	input := inputs[0]
 	split := strings.Split(input, " ")
-	counter := NewCounter()
+	counter := NewCounter(capacity)
 	count := 0
 	for _, s := range split {
 		if pebble, err := strconv.Atoi(s); err == nil {
@@ -74,5 +87,5 @@ func CountPebbles(inputs []string, nBlinks int) int {
 			log.Panicf("Could not parse %q", input)
 		}
 	}
-	return count
+	return count, counter.Stats()
 }
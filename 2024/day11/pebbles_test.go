@@ -17,3 +17,25 @@ func TestCountPebbles(t *testing.T) {
 		}
 	}
 }
+
+func TestCountPebblesCached(t *testing.T) {
+	cases := []struct {
+		inputs   []string
+		nBlinks  int
+		capacity int
+		expected int
+	}{
+		{[]string{"125 17"}, 25, 0, 55312},
+		{[]string{"125 17"}, 25, 10, 55312},
+		{[]string{"0"}, 30, 40, 156451},
+	}
+	for _, c := range cases {
+		result, stats := CountPebblesCached(c.inputs, c.nBlinks, c.capacity)
+		if result != c.expected {
+			t.Errorf("CountPebblesCached(%q, %d, %d) == %d, expected %d", c.inputs, c.nBlinks, c.capacity, result, c.expected)
+		}
+		if c.capacity > 0 && stats.Size > c.capacity {
+			t.Errorf("CountPebblesCached(%q, %d, %d) cached %d entries", c.inputs, c.nBlinks, c.capacity, stats.Size)
+		}
+	}
+}
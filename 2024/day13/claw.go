@@ -1,6 +1,7 @@
 package day13
 
 import (
+	"aoc2024/linalg"
 	"log"
 	"regexp"
 	"strconv"
@@ -11,18 +12,30 @@ const (
 	bCost         = 1
 	buttonPattern = `^Button (A|B): X\+(\d+), Y\+(\d+)$`
 	prizePattern  = `^Prize: X=(\d+), Y=(\d+)$`
-	big           = 10000000000000
+	prizeOffset   = 10000000000000
 )
 
-func gcd(a, b int) int {
+func extendedGCD(a, b int) (int, int, int) {
+	x0, x1, y0, y1 := 1, 0, 0, 1
 	for b != 0 {
+		q := a / b
 		a, b = b, a%b
+		x0, x1 = x1, x0-q*x1
+		y0, y1 = y1, y0-q*y1
 	}
-	return a
+	return a, x0, y0
 }
 
-func lcm(a, b int) int {
-	return a * b / gcd(a, b)
+func floorDiv(a, b int) int {
+	q := a / b
+	if (a%b != 0) && ((a < 0) != (b < 0)) {
+		q--
+	}
+	return q
+}
+
+func ceilDiv(a, b int) int {
+	return -floorDiv(-a, b)
 }
 
 type vector struct {
@@ -76,50 +89,81 @@ func parseMachines(inputs []string) []machine {
 	return machines
 }
 
-func MinCost(inputs []string) int {
+func minCost(m machine) (int, bool) {
+	solution := linalg.SolveInts(
+		[][]int{{m.aVec.x, m.bVec.x}, {m.aVec.y, m.bVec.y}},
+		[]int{m.prizeVec.x, m.prizeVec.y},
+	)
+	if !solution.Consistent {
+		return 0, false
+	}
+	if !solution.Unique {
+		return minCostCollinear(m)
+	}
+	presses, ok := solution.Integers()
+	if !ok || presses[0] < 0 || presses[1] < 0 {
+		return 0, false
+	}
+	return presses[0]*aCost + presses[1]*bCost, true
+}
+
+// minCostCollinear handles machines whose buttons move the claw along the
+// same line as the prize, so that any solution of one axis solves both.
+func minCostCollinear(m machine) (int, bool) {
+	a, b, p := m.aVec.x, m.bVec.x, m.prizeVec.x
+	if a == 0 && b == 0 {
+		a, b, p = m.aVec.y, m.bVec.y, m.prizeVec.y
+	}
+	switch {
+	case a == 0 && b == 0:
+		return 0, p == 0
+	case a == 0:
+		if p%b != 0 || p/b < 0 {
+			return 0, false
+		}
+		return p / b * bCost, true
+	case b == 0:
+		if p%a != 0 || p/a < 0 {
+			return 0, false
+		}
+		return p / a * aCost, true
+	}
+
+	g, x, y := extendedGCD(a, b)
+	if p%g != 0 {
+		return 0, false
+	}
+	a0, b0 := x*(p/g), y*(p/g)
+	aStep, bStep := b/g, a/g
+	kMin, kMax := ceilDiv(-a0, aStep), floorDiv(b0, bStep)
+	if kMin > kMax {
+		return 0, false
+	}
+	cost := func(k int) int {
+		return (a0+k*aStep)*aCost + (b0-k*bStep)*bCost
+	}
+	return min(cost(kMin), cost(kMax)), true
+}
+
+func sumMinCosts(machines []machine) int {
 	sum := 0
-	machines := parseMachines(inputs)
 	for _, m := range machines {
-		lcmA := lcm(m.aVec.x, m.aVec.y)
-		xFact, yFact := lcmA/m.aVec.x, lcmA/m.aVec.y
-		xFactPrize, xFactB := xFact*m.prizeVec.x, xFact*m.bVec.x
-		numerB := xFactPrize - yFact*m.prizeVec.y
-		denom := xFactB - yFact*m.bVec.y
-		bRem := numerB % denom
-		if bRem == 0 {
-			b := numerB / denom
-			numerA := xFactPrize - xFactB*b
-			aRem := numerA % lcmA
-			if aRem == 0 {
-				a := numerA / lcmA
-				sum += a*aCost + b*bCost
-			}
+		if cost, ok := minCost(m); ok {
+			sum += cost
 		}
 	}
 	return sum
 }
 
+func MinCost(inputs []string) int {
+	return sumMinCosts(parseMachines(inputs))
+}
+
 func MinCostBig(inputs []string) int {
-	sum := 0
 	machines := parseMachines(inputs)
-	for _, m := range machines {
-		m.prizeVec.x += big
-		m.prizeVec.y += big
-		lcmA := lcm(m.aVec.x, m.aVec.y)
-		xFact, yFact := lcmA/m.aVec.x, lcmA/m.aVec.y
-		xFactPrize, xFactB := xFact*m.prizeVec.x, xFact*m.bVec.x
-		numerB := xFactPrize - yFact*m.prizeVec.y
-		denom := xFactB - yFact*m.bVec.y
-		bRem := numerB % denom
-		if bRem == 0 {
-			b := numerB / denom
-			numerA := xFactPrize - xFactB*b
-			aRem := numerA % lcmA
-			if aRem == 0 {
-				a := numerA / lcmA
-				sum += a*aCost + b*bCost
-			}
-		}
+	for i := range machines {
+		machines[i].prizeVec.x += prizeOffset
+		machines[i].prizeVec.y += prizeOffset
 	}
-	return sum
+	return sumMinCosts(machines)
 }
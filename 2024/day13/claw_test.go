@@ -80,6 +80,38 @@ func TestMinCost(t *testing.T) {
 			},
 			480,
 		},
+		{
+			[]string{
+				"Button A: X+2, Y+2",
+				"Button B: X+1, Y+1",
+				"Prize: X=10, Y=10",
+			},
+			10,
+		},
+		{
+			[]string{
+				"Button A: X+4, Y+4",
+				"Button B: X+1, Y+1",
+				"Prize: X=9, Y=9",
+			},
+			7,
+		},
+		{
+			[]string{
+				"Button A: X+2, Y+2",
+				"Button B: X+4, Y+4",
+				"Prize: X=5, Y=5",
+			},
+			0,
+		},
+		{
+			[]string{
+				"Button A: X+1, Y+1",
+				"Button B: X+2, Y+2",
+				"Prize: X=3, Y=4",
+			},
+			0,
+		},
 	}
 	for _, c := range cases {
 		result := MinCost(c.inputs)
@@ -88,3 +120,45 @@ func TestMinCost(t *testing.T) {
 		}
 	}
 }
+
+func TestMinCostBig(t *testing.T) {
+	cases := []struct {
+		inputs   []string
+		expected int
+	}{
+		{
+			[]string{
+				"Button A: X+94, Y+34",
+				"Button B: X+22, Y+67",
+				"Prize: X=8400, Y=5400",
+				"",
+				"Button A: X+26, Y+66",
+				"Button B: X+67, Y+21",
+				"Prize: X=12748, Y=12176",
+				"",
+				"Button A: X+17, Y+86",
+				"Button B: X+84, Y+37",
+				"Prize: X=7870, Y=6450",
+				"",
+				"Button A: X+69, Y+23",
+				"Button B: X+27, Y+71",
+				"Prize: X=18641, Y=10279",
+			},
+			875318608908,
+		},
+		{
+			[]string{
+				"Button A: X+4, Y+4",
+				"Button B: X+1, Y+1",
+				"Prize: X=0, Y=0",
+			},
+			7500000000000,
+		},
+	}
+	for _, c := range cases {
+		result := MinCostBig(c.inputs)
+		if result != c.expected {
+			t.Errorf("MinCostBig(%q) == %d, expected %d", c.inputs, result, c.expected)
+		}
+	}
+}
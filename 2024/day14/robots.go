@@ -1,7 +1,8 @@
 package day14
 
 import (
-	"fmt"
+	"aoc2024/cycle"
+	"bytes"
 	"log"
 	"regexp"
 	"strconv"
@@ -13,28 +14,21 @@ type vector struct {
 	x, y int
 }
 
-func add(v1, v2 vector, nrows, ncols int) vector {
-	x := v1.x + v2.x
-	if x < 0 {
-		x = x%ncols + ncols
-	} else {
-		x %= ncols
-	}
-	y := v1.y + v2.y
-	if y < 0 {
-		y = y%nrows + nrows
-	} else {
-		y %= nrows
-	}
-	return vector{x, y}
+func wrap(p, v, t, size int) int {
+	return ((p+v*(t%size))%size + size) % size
 }
 
 type robot struct {
 	position, velocity vector
 }
 
-func (r *robot) update(nrows, ncols int) {
-	r.position = add(r.position, r.velocity, nrows, ncols)
+// at returns where the robot is after t steps, without stepping through the
+// positions in between.
+func (r robot) at(t, nrows, ncols int) vector {
+	return vector{
+		wrap(r.position.x, r.velocity.x, t, ncols),
+		wrap(r.position.y, r.velocity.y, t, nrows),
+	}
 }
 
 func parseRobots(inputs []string) []robot {
@@ -71,23 +65,19 @@ func parseRobots(inputs []string) []robot {
 
 func CalcSafetyFactor(inputs []string, nrows, ncols int, nIter int) int {
 	robots := parseRobots(inputs)
-	for range nIter {
-		for i := range robots {
-			robots[i].update(nrows, ncols)
-		}
-	}
 	sums := [4]int{}
 	midCol := ncols / 2
 	midRow := nrows / 2
 	for _, r := range robots {
+		position := r.at(nIter, nrows, ncols)
 		switch {
-		case r.position.x < midCol && r.position.y < midRow:
+		case position.x < midCol && position.y < midRow:
 			sums[0]++
-		case r.position.x > midCol && r.position.y < midRow:
+		case position.x > midCol && position.y < midRow:
 			sums[1]++
-		case r.position.x < midCol && r.position.y > midRow:
+		case position.x < midCol && position.y > midRow:
 			sums[2]++
-		case r.position.x > midCol && r.position.y > midRow:
+		case position.x > midCol && position.y > midRow:
 			sums[3]++
 		}
 	}
@@ -98,55 +88,82 @@ func CalcSafetyFactor(inputs []string, nrows, ncols int, nIter int) int {
 	return product
 }
 
-func FindSignal(inputs []string, nrows, ncols int) int {
-	robots := parseRobots(inputs)
-	midCol := ncols / 2
-	midRow := nrows / 2
-	expectedSum := len(robots) * midCol * midRow / (ncols * nrows)
-	threshold := 3 * expectedSum / 4
-	i := 0
-Outer:
-	for {
-		for i := range robots {
-			robots[i].update(nrows, ncols)
-		}
-		sums := [4]int{}
-		for _, r := range robots {
-			switch {
-			case r.position.x < midCol && r.position.y < midRow:
-				sums[0]++
-			case r.position.x > midCol && r.position.y < midRow:
-				sums[1]++
-			case r.position.x < midCol && r.position.y > midRow:
-				sums[2]++
-			case r.position.x > midCol && r.position.y > midRow:
-				sums[3]++
-			}
+// variance returns the variance of the coordinates scaled by n^2 to stay in
+// integers.
+func variance(coords []int) int {
+	n, sum, squares := len(coords), 0, 0
+	for _, c := range coords {
+		sum += c
+		squares += c * c
+	}
+	return n*squares - sum*sum
+}
+
+// axisMinimum looks for the step within one period of an axis at which the
+// robots bunch up along it, reporting false when no step stands out.
+func axisMinimum(robots []robot, size int, coord func(robot) (int, int)) (int, bool) {
+	const clusterRatio = 2
+	coords := make([]int, len(robots))
+	best, bestVariance, total := 0, 0, 0
+	for t := range size {
+		for i, r := range robots {
+			p, v := coord(r)
+			coords[i] = wrap(p, v, t, size)
 		}
-		i++
-		for _, n := range sums {
-			diff := n - expectedSum
-			if diff < -threshold || diff > threshold {
-				break Outer
-			}
+		value := variance(coords)
+		if t == 0 || value < bestVariance {
+			best, bestVariance = t, value
 		}
+		total += value
+	}
+	if clusterRatio*bestVariance*size >= total {
+		return 0, false
 	}
-	bitmap := make([][]bool, nrows)
+	return best, true
+}
+
+type Signal struct {
+	Step  int
+	Frame string
+}
+
+func render(robots []robot, t, nrows, ncols int) string {
+	bitmap := make([][]byte, nrows)
 	for i := range bitmap {
-		bitmap[i] = make([]bool, ncols)
+		bitmap[i] = bytes.Repeat([]byte{'.'}, ncols)
 	}
 	for _, r := range robots {
-		bitmap[r.position.y][r.position.x] = true
+		position := r.at(t, nrows, ncols)
+		bitmap[position.y][position.x] = '#'
 	}
-	for _, row := range bitmap {
-		for _, value := range row {
-			if value {
-				fmt.Print("#")
-			} else {
-				fmt.Print(" ")
-			}
-		}
-		fmt.Println()
+	return string(bytes.Join(bitmap, []byte{'\n'}))
+}
+
+// FindSignal finds the first step at which the robots cluster on both axes by
+// combining the per-axis variance minima over the grid period with the CRT.
+func FindSignal(inputs []string, nrows, ncols int) Signal {
+	robots := parseRobots(inputs)
+	tx, okx := axisMinimum(robots, ncols, func(r robot) (int, int) {
+		return r.position.x, r.velocity.x
+	})
+	ty, oky := axisMinimum(robots, nrows, func(r robot) (int, int) {
+		return r.position.y, r.velocity.y
+	})
+	if !okx || !oky {
+		log.Printf("No signal found within the grid period of %d steps", ncols*nrows/gcd(ncols, nrows))
+		return Signal{Step: -1}
+	}
+	step, _, ok := cycle.CRT(tx, ncols, ty, nrows)
+	if !ok {
+		log.Printf("Clusters at steps %d (mod %d) and %d (mod %d) never coincide", tx, ncols, ty, nrows)
+		return Signal{Step: -1}
+	}
+	return Signal{step, render(robots, step, nrows, ncols)}
+}
+
+func gcd(a, b int) int {
+	for b != 0 {
+		a, b = b, a%b
 	}
-	return i
+	return a
 }
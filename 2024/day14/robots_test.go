@@ -1,6 +1,11 @@
 package day14
 
-import "testing"
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+)
 
 func TestCalcSafetyFactor(t *testing.T) {
 	cases := []struct {
@@ -45,3 +50,61 @@ func TestCalcSafetyFactor(t *testing.T) {
 		}
 	}
 }
+
+func TestFindSignalNoSignal(t *testing.T) {
+	inputs := []string{
+		"p=5,0 v=0,1",
+		"p=5,3 v=0,-2",
+	}
+	result := FindSignal(inputs, 7, 11)
+	if result.Step != -1 {
+		t.Errorf("FindSignal(%q, 7, 11).Step == %d, expected -1", inputs, result.Step)
+	}
+}
+
+func TestRobotAt(t *testing.T) {
+	r := robot{vector{2, 4}, vector{2, -3}}
+	const nrows, ncols = 7, 11
+	position := r.position
+	for step := range 200 {
+		if result := r.at(step, nrows, ncols); result != position {
+			t.Errorf("robot.at(%d) == %v, expected %v", step, result, position)
+		}
+		position.x = ((position.x+r.velocity.x)%ncols + ncols) % ncols
+		position.y = ((position.y+r.velocity.y)%nrows + nrows) % nrows
+	}
+}
+
+// convergingRobots builds robots that all land in a 3x3 block in the middle of
+// the grid at the given step.
+func convergingRobots(r *rand.Rand, n, step, nrows, ncols int) []string {
+	inputs := make([]string, n)
+	for i := range inputs {
+		x, y := ncols/2-1+r.Intn(3), nrows/2-1+r.Intn(3)
+		vx, vy := r.Intn(2*ncols-1)-ncols+1, r.Intn(2*nrows-1)-nrows+1
+		x = wrap(x, -vx, step, ncols)
+		y = wrap(y, -vy, step, nrows)
+		inputs[i] = fmt.Sprintf("p=%d,%d v=%d,%d", x, y, vx, vy)
+	}
+	return inputs
+}
+
+func TestFindSignal(t *testing.T) {
+	r := rand.New(rand.NewSource(14))
+	const nrows, ncols = 23, 31
+	for _, step := range []int{0, 17, 400, 712} {
+		inputs := convergingRobots(r, 60, step, nrows, ncols)
+		result := FindSignal(inputs, nrows, ncols)
+		if result.Step != step {
+			t.Errorf("FindSignal(%q, %d, %d).Step == %d, expected %d", inputs, nrows, ncols, result.Step, step)
+			continue
+		}
+		rows := strings.Split(result.Frame, "\n")
+		if len(rows) != nrows || len(rows[0]) != ncols {
+			t.Errorf("FindSignal(...).Frame is %dx%d, expected %dx%d", len(rows), len(rows[0]), nrows, ncols)
+		}
+		if count := strings.Count(result.Frame, "#"); count > 9 {
+			t.Errorf("FindSignal(...).Frame has %d robot tiles, expected at most 9", count)
+		}
+	}
+}
@@ -1,9 +1,10 @@
 package day15
 
 import (
-	"aoc2024/deque"
-	"aoc2024/set"
-	"iter"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
 )
 
 const (
@@ -11,6 +12,7 @@ const (
 	robotChar    = '@'
 	boxChar      = 'O'
 	boxLeftChar  = '['
+	boxMidChar   = '='
 	boxRightChar = ']'
 	wallChar     = '#'
 	upChar       = '^'
@@ -19,54 +21,46 @@ const (
 	rightChar    = '>'
 )
 
+const (
+	emptyCell = -1
+	wallCell  = -2
+)
+
 type vector struct {
 	x, y int
 }
 
-type grid struct {
-	nrows, ncols int
-	values       []byte
-}
-
-func newGrid(nrows, ncols int) *grid {
-	return &grid{
-		nrows:  nrows,
-		ncols:  ncols,
-		values: make([]byte, nrows*ncols),
-	}
-}
-
-func (g *grid) get(v vector) byte {
-	row := g.nrows - v.y - 1
-	return g.values[row*g.ncols+v.x]
+var directions = map[byte]vector{
+	upChar:    {0, -1},
+	downChar:  {0, 1},
+	leftChar:  {-1, 0},
+	rightChar: {1, 0},
 }
 
-func (g *grid) set(v vector, c byte) {
-	row := g.nrows - v.y - 1
-	g.values[row*g.ncols+v.x] = c
-}
-
-func (g *grid) all() iter.Seq2[vector, byte] {
-	return func(yield func(vector, byte) bool) {
-		for i, c := range g.values {
-			row := i / g.ncols
-			col := i % g.ncols
-			v := vector{col, g.nrows - row - 1}
-			if !yield(v, c) {
-				break
-			}
-		}
-	}
+// moveEvent records one move of the robot so it can be undone.
+type moveEvent struct {
+	move   byte
+	from   vector
+	moved  bool
+	pushed []int
 }
 
+// warehouse holds boxes boxWidth cells wide. Rows count down from the top
+// and each box is stored by its leftmost cell.
 type warehouse struct {
-	grid        *grid
-	robotPos    vector
-	moves       []byte
-	currentMove int
+	nrows, ncols int
+	boxWidth     int
+	cells        []int
+	boxes        []vector
+	robot        vector
+	moves        []byte
+	events       []moveEvent
 }
 
-func parseWarehouse(inputs []string) warehouse {
+func parseWarehouse(inputs []string, boxWidth int) (*warehouse, error) {
+	if boxWidth < 1 {
+		return nil, fmt.Errorf("box width %d is not positive", boxWidth)
+	}
 	gridRows := []string{}
 	moveRows := []string{}
 	inGrid := true
@@ -81,328 +75,218 @@ func parseWarehouse(inputs []string) warehouse {
 			moveRows = append(moveRows, row)
 		}
 	}
-	nrows := len(gridRows)
-	ncols := len(gridRows[0])
-	g := newGrid(nrows, ncols)
-	var robotPos vector
-	for i, row := range gridRows {
-		for j := range row {
-			v := vector{j, nrows - i - 1}
-			c := row[j]
-			g.set(v, c)
-			if c == robotChar {
-				robotPos = v
+	if len(gridRows) == 0 {
+		return nil, errors.New("warehouse has no rows")
+	}
+	nrows, ncols := len(gridRows), len(gridRows[0])
+	w := &warehouse{
+		nrows:    nrows,
+		ncols:    boxWidth * ncols,
+		boxWidth: boxWidth,
+		cells:    make([]int, nrows*boxWidth*ncols),
+		boxes:    []vector{},
+	}
+	robots := 0
+	for y, row := range gridRows {
+		if len(row) != ncols {
+			return nil, fmt.Errorf("row %d has %d columns, expected %d", y, len(row), ncols)
+		}
+		for x := range row {
+			c := row[x]
+			border := y == 0 || y == nrows-1 || x == 0 || x == ncols-1
+			if border && c != wallChar {
+				return nil, fmt.Errorf("border at row %d, column %d is %q, not a wall", y, x, c)
+			}
+			v := vector{boxWidth * x, y}
+			fill := emptyCell
+			switch c {
+			case emptyChar:
+			case wallChar:
+				fill = wallCell
+			case robotChar:
+				w.robot = v
+				robots++
+			case boxChar:
+				fill = len(w.boxes)
+				w.boxes = append(w.boxes, v)
+			default:
+				return nil, fmt.Errorf("unexpected %q at row %d, column %d", c, y, x)
+			}
+			for i := range boxWidth {
+				w.set(vector{v.x + i, y}, fill)
 			}
 		}
 	}
-	moves := []byte{}
+	if robots != 1 {
+		return nil, fmt.Errorf("warehouse has %d robots, expected 1", robots)
+	}
+	w.moves = []byte{}
 	for _, row := range moveRows {
 		for i := range row {
-			moves = append(moves, row[i])
+			if _, ok := directions[row[i]]; !ok {
+				return nil, fmt.Errorf("unexpected move %q", row[i])
+			}
+			w.moves = append(w.moves, row[i])
 		}
 	}
-	return warehouse{
-		grid:        g,
-		robotPos:    robotPos,
-		moves:       moves,
-		currentMove: 0,
-	}
+	w.events = make([]moveEvent, 0, len(w.moves))
+	return w, nil
 }
 
-func (w *warehouse) update() bool {
-	if w.currentMove >= len(w.moves) {
-		return false
-	}
-	move := w.moves[w.currentMove]
-	switch move {
-	case upChar:
-		for y := w.robotPos.y + 1; y < w.grid.nrows; y++ {
-			c := w.grid.get(vector{w.robotPos.x, y})
-			if c == emptyChar {
-				newRobotPos := vector{w.robotPos.x, w.robotPos.y + 1}
-				w.grid.set(vector{w.robotPos.x, y}, boxChar)
-				w.grid.set(newRobotPos, robotChar)
-				w.grid.set(vector{w.robotPos.x, w.robotPos.y}, emptyChar)
-				w.robotPos = newRobotPos
-				break
-			} else if c == wallChar {
-				break
-			}
-		}
-	case downChar:
-		for y := w.robotPos.y - 1; y >= 0; y-- {
-			c := w.grid.get(vector{w.robotPos.x, y})
-			if c == emptyChar {
-				newRobotPos := vector{w.robotPos.x, w.robotPos.y - 1}
-				w.grid.set(vector{w.robotPos.x, y}, boxChar)
-				w.grid.set(newRobotPos, robotChar)
-				w.grid.set(vector{w.robotPos.x, w.robotPos.y}, emptyChar)
-				w.robotPos = newRobotPos
-				break
-			} else if c == wallChar {
-				break
+func (w *warehouse) get(v vector) int {
+	return w.cells[v.y*w.ncols+v.x]
+}
+
+func (w *warehouse) set(v vector, cell int) {
+	w.cells[v.y*w.ncols+v.x] = cell
+}
+
+// push finds the boxes that moving from v in direction d would push, or
+// reports that a wall blocks them.
+func (w *warehouse) push(v, d vector) ([]int, bool) {
+	pushed := []int{}
+	seen := map[int]bool{}
+	front := []vector{{v.x + d.x, v.y + d.y}}
+	for len(front) > 0 {
+		next := front[len(front)-1]
+		front = front[:len(front)-1]
+		cell := w.get(next)
+		switch {
+		case cell == wallCell:
+			return nil, false
+		case cell >= 0 && !seen[cell]:
+			seen[cell] = true
+			pushed = append(pushed, cell)
+			box := w.boxes[cell]
+			for i := range w.boxWidth {
+				ahead := vector{box.x + i + d.x, box.y + d.y}
+				if w.get(ahead) != cell {
+					front = append(front, ahead)
+				}
 			}
 		}
-	case leftChar:
-		for x := w.robotPos.x - 1; x >= 0; x-- {
-			c := w.grid.get(vector{x, w.robotPos.y})
-			if c == emptyChar {
-				newRobotPos := vector{w.robotPos.x - 1, w.robotPos.y}
-				w.grid.set(vector{x, w.robotPos.y}, boxChar)
-				w.grid.set(newRobotPos, robotChar)
-				w.grid.set(vector{w.robotPos.x, w.robotPos.y}, emptyChar)
-				w.robotPos = newRobotPos
-				break
-			} else if c == wallChar {
-				break
-			}
+	}
+	return pushed, true
+}
+
+func (w *warehouse) shiftBoxes(boxes []int, d vector) {
+	for _, b := range boxes {
+		for i := range w.boxWidth {
+			w.set(vector{w.boxes[b].x + i, w.boxes[b].y}, emptyCell)
 		}
-	case rightChar:
-		for x := w.robotPos.x + 1; x < w.grid.ncols; x++ {
-			c := w.grid.get(vector{x, w.robotPos.y})
-			if c == emptyChar {
-				newRobotPos := vector{w.robotPos.x + 1, w.robotPos.y}
-				w.grid.set(vector{x, w.robotPos.y}, boxChar)
-				w.grid.set(newRobotPos, robotChar)
-				w.grid.set(vector{w.robotPos.x, w.robotPos.y}, emptyChar)
-				w.robotPos = newRobotPos
-				break
-			} else if c == wallChar {
-				break
-			}
+	}
+	for _, b := range boxes {
+		w.boxes[b] = vector{w.boxes[b].x + d.x, w.boxes[b].y + d.y}
+		for i := range w.boxWidth {
+			w.set(vector{w.boxes[b].x + i, w.boxes[b].y}, b)
 		}
 	}
-	w.currentMove++
-	return true
 }
 
-func (w *warehouse) sumCoordinates() int {
-	sum := 0
-	for v, c := range w.grid.all() {
-		if c == boxChar {
-			sum += 100*(w.grid.nrows-v.y-1) + v.x
-		}
+func (w *warehouse) update() bool {
+	if len(w.events) >= len(w.moves) {
+		return false
 	}
-	return sum
+	move := w.moves[len(w.events)]
+	d := directions[move]
+	event := moveEvent{move: move, from: w.robot}
+	if pushed, ok := w.push(w.robot, d); ok {
+		w.shiftBoxes(pushed, d)
+		w.robot = vector{w.robot.x + d.x, w.robot.y + d.y}
+		event.moved = true
+		event.pushed = pushed
+	}
+	w.events = append(w.events, event)
+	return true
 }
 
-type wideWarehouse struct {
-	grid        *grid
-	robotPos    vector
-	moves       []byte
-	currentMove int
+func (w *warehouse) undo() bool {
+	if len(w.events) == 0 {
+		return false
+	}
+	event := w.events[len(w.events)-1]
+	w.events = w.events[:len(w.events)-1]
+	if event.moved {
+		d := directions[event.move]
+		w.shiftBoxes(event.pushed, vector{-d.x, -d.y})
+		w.robot = event.from
+	}
+	return true
 }
 
-func newWideWarehouse(w warehouse) wideWarehouse {
-	var robotPos vector
-	wideGrid := newGrid(w.grid.nrows, 2*w.grid.ncols)
-	for v, c := range w.grid.all() {
-		wideVecs := [2]vector{{2 * v.x, v.y}, {2*v.x + 1, v.y}}
-		switch c {
-		case emptyChar:
-			wideGrid.set(wideVecs[0], emptyChar)
-			wideGrid.set(wideVecs[1], emptyChar)
-		case robotChar:
-			wideGrid.set(wideVecs[0], robotChar)
-			wideGrid.set(wideVecs[1], emptyChar)
-			robotPos = wideVecs[0]
-		case boxChar:
-			wideGrid.set(wideVecs[0], boxLeftChar)
-			wideGrid.set(wideVecs[1], boxRightChar)
-		case wallChar:
-			wideGrid.set(wideVecs[0], wallChar)
-			wideGrid.set(wideVecs[1], wallChar)
-		}
+// seek replays or undoes moves until exactly n have been made.
+func (w *warehouse) seek(n int) {
+	n = max(0, min(n, len(w.moves)))
+	for len(w.events) > n {
+		w.undo()
 	}
-	return wideWarehouse{
-		grid:        wideGrid,
-		robotPos:    robotPos,
-		moves:       w.moves,
-		currentMove: 0,
+	for len(w.events) < n {
+		w.update()
 	}
 }
 
-func (w *wideWarehouse) update() bool {
-	if w.currentMove >= len(w.moves) {
-		return false
+func (w *warehouse) sumCoordinates() int {
+	sum := 0
+	for _, box := range w.boxes {
+		sum += 100*box.y + box.x
 	}
-	updateVecs := set.NewSet[vector]()
-	frontVecs := deque.NewDeque[vector](-1)
-	move := w.moves[w.currentMove]
-	switch move {
-	case upChar:
-		updateVecs.Add(vector{w.robotPos.x, w.robotPos.y})
-		frontVecs.Append(vector{w.robotPos.x, w.robotPos.y + 1})
-	frontLoopUp:
-		for {
-			if frontVec, ok := frontVecs.Pop(); ok {
-				c := w.grid.get(frontVec)
-				switch c {
-				case boxLeftChar:
-					updateVecs.Add(vector{frontVec.x, frontVec.y})
-					updateVecs.Add(vector{frontVec.x + 1, frontVec.y})
-					frontVecs.Append(vector{frontVec.x, frontVec.y + 1})
-					frontVecs.Append(vector{frontVec.x + 1, frontVec.y + 1})
-				case boxRightChar:
-					updateVecs.Add(vector{frontVec.x, frontVec.y})
-					updateVecs.Add(vector{frontVec.x - 1, frontVec.y})
-					frontVecs.Append(vector{frontVec.x, frontVec.y + 1})
-					frontVecs.Append(vector{frontVec.x - 1, frontVec.y + 1})
-				case wallChar:
-					updateVecs.Clear()
-					break frontLoopUp
-				}
-			} else {
-				break frontLoopUp
-			}
-		}
-		for updateVecs.Len() > 0 {
-			for updateVec := range updateVecs.All() {
-				nextVec := vector{updateVec.x, updateVec.y + 1}
-				if w.grid.get(nextVec) == emptyChar {
-					c := w.grid.get(updateVec)
-					w.grid.set(nextVec, c)
-					w.grid.set(updateVec, emptyChar)
-					if c == robotChar {
-						w.robotPos = nextVec
-					}
-					updateVecs.Remove(updateVec)
-				}
-			}
-		}
-	case downChar:
-		updateVecs.Add(vector{w.robotPos.x, w.robotPos.y})
-		frontVecs.Append(vector{w.robotPos.x, w.robotPos.y - 1})
-	frontLoopDown:
-		for {
-			if frontVec, ok := frontVecs.Pop(); ok {
-				c := w.grid.get(frontVec)
-				switch c {
-				case boxLeftChar:
-					updateVecs.Add(vector{frontVec.x, frontVec.y})
-					updateVecs.Add(vector{frontVec.x + 1, frontVec.y})
-					frontVecs.Append(vector{frontVec.x, frontVec.y - 1})
-					frontVecs.Append(vector{frontVec.x + 1, frontVec.y - 1})
-				case boxRightChar:
-					updateVecs.Add(vector{frontVec.x, frontVec.y})
-					updateVecs.Add(vector{frontVec.x - 1, frontVec.y})
-					frontVecs.Append(vector{frontVec.x, frontVec.y - 1})
-					frontVecs.Append(vector{frontVec.x - 1, frontVec.y - 1})
-				case wallChar:
-					updateVecs.Clear()
-					break frontLoopDown
-				}
-			} else {
-				break frontLoopDown
-			}
-		}
-		for updateVecs.Len() > 0 {
-			for updateVec := range updateVecs.All() {
-				nextVec := vector{updateVec.x, updateVec.y - 1}
-				if w.grid.get(nextVec) == emptyChar {
-					c := w.grid.get(updateVec)
-					w.grid.set(nextVec, c)
-					w.grid.set(updateVec, emptyChar)
-					if c == robotChar {
-						w.robotPos = nextVec
-					}
-					updateVecs.Remove(updateVec)
-				}
-			}
-		}
-	case leftChar:
-		updateVecs.Add(vector{w.robotPos.x, w.robotPos.y})
-		frontVecs.Append(vector{w.robotPos.x - 1, w.robotPos.y})
-	frontLoopLeft:
-		for {
-			if frontVec, ok := frontVecs.Pop(); ok {
-				c := w.grid.get(frontVec)
-				switch c {
-				case boxRightChar:
-					updateVecs.Add(vector{frontVec.x, frontVec.y})
-					updateVecs.Add(vector{frontVec.x - 1, frontVec.y})
-					frontVecs.Append(vector{frontVec.x - 2, frontVec.y})
-				case wallChar:
-					updateVecs.Clear()
-					break frontLoopLeft
-				}
-			} else {
-				break frontLoopLeft
-			}
-		}
-		for updateVecs.Len() > 0 {
-			for updateVec := range updateVecs.All() {
-				nextVec := vector{updateVec.x - 1, updateVec.y}
-				if w.grid.get(nextVec) == emptyChar {
-					c := w.grid.get(updateVec)
-					w.grid.set(nextVec, c)
-					w.grid.set(updateVec, emptyChar)
-					if c == robotChar {
-						w.robotPos = nextVec
-					}
-					updateVecs.Remove(updateVec)
-				}
-			}
-		}
-	case rightChar:
-		updateVecs.Add(vector{w.robotPos.x, w.robotPos.y})
-		frontVecs.Append(vector{w.robotPos.x + 1, w.robotPos.y})
-	frontLoopRight:
-		for {
-			if frontVec, ok := frontVecs.Pop(); ok {
-				c := w.grid.get(frontVec)
-				switch c {
-				case boxLeftChar:
-					updateVecs.Add(vector{frontVec.x, frontVec.y})
-					updateVecs.Add(vector{frontVec.x + 1, frontVec.y})
-					frontVecs.Append(vector{frontVec.x + 2, frontVec.y})
-				case wallChar:
-					updateVecs.Clear()
-					break frontLoopRight
-				}
-			} else {
-				break frontLoopRight
-			}
-		}
-		for updateVecs.Len() > 0 {
-			for updateVec := range updateVecs.All() {
-				nextVec := vector{updateVec.x + 1, updateVec.y}
-				if w.grid.get(nextVec) == emptyChar {
-					c := w.grid.get(updateVec)
-					w.grid.set(nextVec, c)
-					w.grid.set(updateVec, emptyChar)
-					if c == robotChar {
-						w.robotPos = nextVec
-					}
-					updateVecs.Remove(updateVec)
-				}
+	return sum
+}
+
+func (w *warehouse) String() string {
+	var b strings.Builder
+	for y := range w.nrows {
+		for x := range w.ncols {
+			v := vector{x, y}
+			cell := w.get(v)
+			switch {
+			case v == w.robot:
+				b.WriteByte(robotChar)
+			case cell == wallCell:
+				b.WriteByte(wallChar)
+			case cell == emptyCell:
+				b.WriteByte(emptyChar)
+			case w.boxWidth == 1:
+				b.WriteByte(boxChar)
+			case x == w.boxes[cell].x:
+				b.WriteByte(boxLeftChar)
+			case x == w.boxes[cell].x+w.boxWidth-1:
+				b.WriteByte(boxRightChar)
+			default:
+				b.WriteByte(boxMidChar)
 			}
 		}
+		b.WriteByte('\n')
 	}
-	w.currentMove++
-	return true
+	return b.String()
 }
 
-func (w *wideWarehouse) sumCoordinates() int {
-	sum := 0
-	for v, c := range w.grid.all() {
-		if c == boxLeftChar {
-			sum += 100*(w.grid.nrows-v.y-1) + v.x
-		}
+func mustParseWarehouse(inputs []string, boxWidth int) *warehouse {
+	w, err := parseWarehouse(inputs, boxWidth)
+	if err != nil {
+		log.Panicf("Invalid warehouse: %v", err)
 	}
-	return sum
+	return w
 }
 
-func SumCoordinates(inputs []string) int {
-	w := parseWarehouse(inputs)
+func SumCoordinatesWidth(inputs []string, boxWidth int) int {
+	w := mustParseWarehouse(inputs, boxWidth)
 	for w.update() {
 	}
 	return w.sumCoordinates()
 }
 
+func SumCoordinates(inputs []string) int {
+	return SumCoordinatesWidth(inputs, 1)
+}
+
 func SumCoordinatesWide(inputs []string) int {
-	w := newWideWarehouse(parseWarehouse(inputs))
-	for w.update() {
-	}
-	return w.sumCoordinates()
+	return SumCoordinatesWidth(inputs, 2)
+}
+
+// RenderWarehouse draws the warehouse after the first nMoves moves.
+func RenderWarehouse(inputs []string, boxWidth int, nMoves int) string {
+	w := mustParseWarehouse(inputs, boxWidth)
+	w.seek(nMoves)
+	return w.String()
 }
@@ -1,6 +1,9 @@
 package day15
 
-import "testing"
+import (
+	"strings"
+	"testing"
+)
 
 func TestSumCoordinates(t *testing.T) {
 	cases := []struct {
@@ -110,3 +113,151 @@ func TestSumCoordinatesWide(t *testing.T) {
 		}
 	}
 }
+
+var smallExample = []string{
+	"########",
+	"#..O.O.#",
+	"##@.O..#",
+	"#...O..#",
+	"#.#.O..#",
+	"#...O..#",
+	"#......#",
+	"########",
+	"",
+	"<^^>>>vv<v>>v<<",
+}
+
+var wideExample = []string{
+	"#######",
+	"#...#.#",
+	"#.....#",
+	"#..OO@#",
+	"#..O..#",
+	"#.....#",
+	"#######",
+	"",
+	"<vv<<^^<<^^",
+}
+
+func TestRenderWarehouse(t *testing.T) {
+	cases := []struct {
+		inputs   []string
+		boxWidth int
+		nMoves   int
+		expected []string
+	}{
+		{
+			inputs:   smallExample,
+			boxWidth: 1,
+			nMoves:   15,
+			expected: []string{
+				"########",
+				"#....OO#",
+				"##.....#",
+				"#.....O#",
+				"#.#O@..#",
+				"#...O..#",
+				"#...O..#",
+				"########",
+			},
+		},
+		{
+			inputs:   wideExample,
+			boxWidth: 2,
+			nMoves:   11,
+			expected: []string{
+				"##############",
+				"##...[].##..##",
+				"##...@.[]...##",
+				"##....[]....##",
+				"##..........##",
+				"##..........##",
+				"##############",
+			},
+		},
+		{
+			inputs:   wideExample,
+			boxWidth: 3,
+			nMoves:   1,
+			expected: []string{
+				"#####################",
+				"###.........###...###",
+				"###...............###",
+				"###.....[=][=]@...###",
+				"###......[=]......###",
+				"###...............###",
+				"#####################",
+			},
+		},
+	}
+	for _, c := range cases {
+		result := RenderWarehouse(c.inputs, c.boxWidth, c.nMoves)
+		expected := strings.Join(c.expected, "\n") + "\n"
+		if result != expected {
+			t.Errorf("RenderWarehouse(%q, %d, %d) ==\n%s\nexpected\n%s", c.inputs, c.boxWidth, c.nMoves, result, expected)
+		}
+	}
+}
+
+func TestSumCoordinatesWidth(t *testing.T) {
+	cases := []struct {
+		inputs   []string
+		boxWidth int
+		expected int
+	}{
+		{smallExample, 1, 2028},
+		{wideExample, 2, 618},
+		{[]string{"#######", "#.OO@.#", "#######", "", "<<<"}, 4, 100 + 5 + 100 + 9},
+		{[]string{"#####", "#...#", "#.O.#", "#.@.#", "#####", "", "^^"}, 3, 100 + 6},
+	}
+	for _, c := range cases {
+		result := SumCoordinatesWidth(c.inputs, c.boxWidth)
+		if result != c.expected {
+			t.Errorf("SumCoordinatesWidth(%q, %d) == %d, expected %d", c.inputs, c.boxWidth, result, c.expected)
+		}
+	}
+}
+
+func TestWarehouseUndo(t *testing.T) {
+	for boxWidth := 1; boxWidth <= 4; boxWidth++ {
+		w := mustParseWarehouse(wideExample, boxWidth)
+		states := []string{w.String()}
+		for w.update() {
+			states = append(states, w.String())
+		}
+		for n := len(states) - 1; n >= 0; n-- {
+			w.seek(n)
+			if w.String() != states[n] {
+				t.Errorf("width %d after undoing to move %d ==\n%s\nexpected\n%s", boxWidth, n, w, states[n])
+			}
+		}
+		w.seek(len(states))
+		if w.String() != states[len(states)-1] {
+			t.Errorf("width %d replay ==\n%s\nexpected\n%s", boxWidth, w, states[len(states)-1])
+		}
+	}
+}
+
+func TestParseWarehouseErrors(t *testing.T) {
+	cases := []struct {
+		inputs  []string
+		message string
+	}{
+		{[]string{"", "<"}, "no rows"},
+		{[]string{"####", "#@.#", "###", "", "<"}, "columns"},
+		{[]string{"####", "#@..", "####", "", "<"}, "not a wall"},
+		{[]string{"####", "#..#", "####", "", "<"}, "0 robots"},
+		{[]string{"####", "#@@#", "####", "", "<"}, "2 robots"},
+		{[]string{"####", "#@x#", "####", "", "<"}, "unexpected 'x'"},
+		{[]string{"####", "#@.#", "####", "", "<x"}, "unexpected move"},
+	}
+	for _, c := range cases {
+		_, err := parseWarehouse(c.inputs, 1)
+		if err == nil || !strings.Contains(err.Error(), c.message) {
+			t.Errorf("parseWarehouse(%q) error == %v, expected %q", c.inputs, err, c.message)
+		}
+	}
+	if _, err := parseWarehouse(smallExample, 0); err == nil {
+		t.Errorf("parseWarehouse accepted box width 0")
+	}
+}
@@ -1,9 +1,10 @@
 package day16
 
 import (
-	"aoc2024/deque"
 	"aoc2024/set"
+	"fmt"
 	"iter"
+	"slices"
 )
 
 const (
@@ -59,6 +60,25 @@ func (g *grid) all() iter.Seq2[vector, byte] {
 	}
 }
 
+func (g *grid) clone() *grid {
+	return &grid{
+		nrows:  g.nrows,
+		ncols:  g.ncols,
+		values: slices.Clone(g.values),
+	}
+}
+
+func (g grid) String() string {
+	s := []byte{}
+	for i, c := range g.values {
+		s = append(s, c)
+		if i%g.ncols == g.ncols-1 {
+			s = append(s, '\n')
+		}
+	}
+	return string(s)
+}
+
 type connection struct {
 	nodeId     int
 	edgeWeight int
@@ -139,6 +159,38 @@ type state struct {
 	orientation byte
 }
 
+// Costs are the scores for stepping forward one tile and for turning 90
+// degrees on the spot.
+type Costs struct {
+	Move, Turn int
+}
+
+var DefaultCosts = Costs{Move: 1, Turn: 1000}
+
+// Validate rejects costs that are not positive, since Dijkstra needs every
+// step to add to the score.
+func (c Costs) Validate() error {
+	if c.Move <= 0 || c.Turn <= 0 {
+		return fmt.Errorf("costs %+v must be positive", c)
+	}
+	return nil
+}
+
+var steps = map[byte]vector{
+	upChar:    {0, 1},
+	downChar:  {0, -1},
+	leftChar:  {-1, 0},
+	rightChar: {1, 0},
+}
+
+// turns lists the orientations after turning left and right.
+var turns = map[byte][2]byte{
+	upChar:    {leftChar, rightChar},
+	downChar:  {rightChar, leftChar},
+	leftChar:  {downChar, upChar},
+	rightChar: {upChar, downChar},
+}
+
 type maze struct {
 	grid    *grid
 	graph   *graph[state]
@@ -160,7 +212,7 @@ func parseGrid(inputs []string) *grid {
 	return g
 }
 
-func parseMaze(inputs []string) maze {
+func parseMaze(inputs []string, c Costs) maze {
 	var (
 		startId                                   int
 		endIds                                    [4]int
@@ -192,122 +244,21 @@ func parseMaze(inputs []string) maze {
 		}
 	}
 	for nodeId, node := range gra.allNodes() {
-		switch node.orientation {
-		case upChar:
-			forward := state{
-				position:    vector{node.position.x, node.position.y + 1},
-				orientation: upChar,
-			}
-			if gri.get(forward.position) != wallChar {
-				gra.addEdge(nodeId, connection{
-					nodeId:     nodeIds[forward],
-					edgeWeight: 1,
-				})
-			}
-
-			turnLeft := state{
-				position:    vector{node.position.x, node.position.y},
-				orientation: leftChar,
-			}
-			gra.addEdge(nodeId, connection{
-				nodeId:     nodeIds[turnLeft],
-				edgeWeight: 1000,
-			})
-
-			turnRight := state{
-				position:    vector{node.position.x, node.position.y},
-				orientation: rightChar,
-			}
-			gra.addEdge(nodeId, connection{
-				nodeId:     nodeIds[turnRight],
-				edgeWeight: 1000,
-			})
-		case downChar:
-			forward := state{
-				position:    vector{node.position.x, node.position.y - 1},
-				orientation: downChar,
-			}
-			if gri.get(forward.position) != wallChar {
-				gra.addEdge(nodeId, connection{
-					nodeId:     nodeIds[forward],
-					edgeWeight: 1,
-				})
-			}
-
-			turnLeft := state{
-				position:    vector{node.position.x, node.position.y},
-				orientation: rightChar,
-			}
-			gra.addEdge(nodeId, connection{
-				nodeId:     nodeIds[turnLeft],
-				edgeWeight: 1000,
-			})
-
-			turnRight := state{
-				position:    vector{node.position.x, node.position.y},
-				orientation: leftChar,
-			}
-			gra.addEdge(nodeId, connection{
-				nodeId:     nodeIds[turnRight],
-				edgeWeight: 1000,
-			})
-		case leftChar:
-			forward := state{
-				position:    vector{node.position.x - 1, node.position.y},
-				orientation: leftChar,
-			}
-			if gri.get(forward.position) != wallChar {
-				gra.addEdge(nodeId, connection{
-					nodeId:     nodeIds[forward],
-					edgeWeight: 1,
-				})
-			}
-
-			turnLeft := state{
-				position:    vector{node.position.x, node.position.y},
-				orientation: downChar,
-			}
-			gra.addEdge(nodeId, connection{
-				nodeId:     nodeIds[turnLeft],
-				edgeWeight: 1000,
-			})
-
-			turnRight := state{
-				position:    vector{node.position.x, node.position.y},
-				orientation: upChar,
-			}
-			gra.addEdge(nodeId, connection{
-				nodeId:     nodeIds[turnRight],
-				edgeWeight: 1000,
-			})
-		case rightChar:
-			forward := state{
-				position:    vector{node.position.x + 1, node.position.y},
-				orientation: rightChar,
-			}
-			if gri.get(forward.position) != wallChar {
-				gra.addEdge(nodeId, connection{
-					nodeId:     nodeIds[forward],
-					edgeWeight: 1,
-				})
-			}
-
-			turnLeft := state{
-				position:    vector{node.position.x, node.position.y},
-				orientation: upChar,
-			}
+		step := steps[node.orientation]
+		forward := state{
+			position:    vector{node.position.x + step.x, node.position.y + step.y},
+			orientation: node.orientation,
+		}
+		if gri.get(forward.position) != wallChar {
 			gra.addEdge(nodeId, connection{
-				nodeId:     nodeIds[turnLeft],
-				edgeWeight: 1000,
+				nodeId:     nodeIds[forward],
+				edgeWeight: c.Move,
 			})
-
-			turnRight := state{
-				position:    vector{node.position.x, node.position.y},
-				orientation: downChar,
-			}
+		}
+		for _, orientation := range turns[node.orientation] {
 			gra.addEdge(nodeId, connection{
-				nodeId:     nodeIds[turnRight],
-				edgeWeight: 1000,
+				nodeId:     nodeIds[state{node.position, orientation}],
+				edgeWeight: c.Turn,
 			})
 		}
 	}
@@ -319,41 +270,45 @@ func parseMaze(inputs []string) maze {
 	}
 }
 
+func parseMazeWithCosts(inputs []string, c Costs) (maze, error) {
+	if err := c.Validate(); err != nil {
+		return maze{}, err
+	}
+	return parseMaze(inputs, c), nil
+}
+
 func MinScore(inputs []string) int {
-	maze := parseMaze(inputs)
+	score, _ := MinScoreWithCosts(inputs, DefaultCosts)
+	return score
+}
+
+func MinScoreWithCosts(inputs []string, c Costs) (int, error) {
+	maze, err := parseMazeWithCosts(inputs, c)
+	if err != nil {
+		return 0, err
+	}
 	dists, _ := dijkstra(maze.graph, maze.startId)
 	return min(
 		dists[maze.endIds[0]],
 		dists[maze.endIds[1]],
 		dists[maze.endIds[2]],
 		dists[maze.endIds[3]],
-	)
+	), nil
 }
 
 func CountTiles(inputs []string) int {
-	maze := parseMaze(inputs)
-	dists, prevs := dijkstra(maze.graph, maze.startId)
-	endIdIndex := 0
-	minDist := dists[maze.endIds[endIdIndex]]
-	for j := range maze.endIds[1:] {
-		if dists[maze.endIds[j]] < minDist {
-			minDist = dists[maze.endIds[j]]
-			endIdIndex = j
-		}
+	count, _ := CountTilesWithCosts(inputs, DefaultCosts)
+	return count
+}
+
+func CountTilesWithCosts(inputs []string, c Costs) (int, error) {
+	maze, err := parseMazeWithCosts(inputs, c)
+	if err != nil {
+		return 0, err
 	}
-	ids := deque.NewDeque[int](-1)
-	ids.Append(maze.endIds[endIdIndex])
 	positions := set.NewSet[vector]()
-	for {
-		if id, ok := ids.Pop(); ok {
-			node := maze.graph.nodes[id]
-			positions.Add(node.position)
-			for _, prevId := range prevs[id] {
-				ids.Append(prevId)
-			}
-		} else {
-			break
-		}
+	for id := range maze.optimalStates() {
+		positions.Add(maze.graph.nodes[id].position)
 	}
-	return positions.Len()
+	return positions.Len(), nil
 }
@@ -0,0 +1,167 @@
+package day16
+
+import (
+	"aoc2024/deque"
+	"aoc2024/set"
+	"iter"
+	"slices"
+	"strings"
+)
+
+const (
+	forwardAction   = 'F'
+	turnLeftAction  = 'L'
+	turnRightAction = 'R'
+	tileChar        = 'O'
+)
+
+// solve runs dijkstra from the start and returns the distances, the
+// predecessors and the end states reached with the lowest score.
+func (m maze) solve() ([]int, [][]int, []int) {
+	dists, prevs := dijkstra(m.graph, m.startId)
+	best := maxInt
+	for _, id := range m.endIds {
+		best = min(best, dists[id])
+	}
+	ends := []int{}
+	if best == maxInt {
+		return dists, prevs, ends
+	}
+	for _, id := range m.endIds {
+		if dists[id] == best {
+			ends = append(ends, id)
+		}
+	}
+	return dists, prevs, ends
+}
+
+// optimalStates yields each state lying on at least one optimal path.
+func (m maze) optimalStates() iter.Seq[int] {
+	return func(yield func(int) bool) {
+		_, prevs, ends := m.solve()
+		seen := set.NewSet[int]()
+		ids := deque.NewDeque[int](-1)
+		for _, id := range ends {
+			seen.Add(id)
+			ids.Append(id)
+		}
+		for {
+			id, ok := ids.Pop()
+			if !ok {
+				return
+			}
+			if !yield(id) {
+				return
+			}
+			for _, prevId := range prevs[id] {
+				if !seen.Contains(prevId) {
+					seen.Add(prevId)
+					ids.Append(prevId)
+				}
+			}
+		}
+	}
+}
+
+// optimalPaths yields the state ids of every optimal path from the start
+// to an end state, walking the predecessor lists depth first.
+func (m maze) optimalPaths() iter.Seq[[]int] {
+	return func(yield func([]int) bool) {
+		_, prevs, ends := m.solve()
+		var walk func(path []int) bool
+		walk = func(path []int) bool {
+			id := path[len(path)-1]
+			if id == m.startId {
+				forward := slices.Clone(path)
+				slices.Reverse(forward)
+				return yield(forward)
+			}
+			for _, prevId := range prevs[id] {
+				if !walk(append(path, prevId)) {
+					return false
+				}
+			}
+			return true
+		}
+		for _, id := range ends {
+			if !walk([]int{id}) {
+				return
+			}
+		}
+	}
+}
+
+// actions describes a path as F for each step forward and L or R for each
+// turn.
+func (m maze) actions(path []int) string {
+	b := []byte{}
+	for i := 1; i < len(path); i++ {
+		from, to := m.graph.nodes[path[i-1]], m.graph.nodes[path[i]]
+		switch to.orientation {
+		case from.orientation:
+			b = append(b, forwardAction)
+		case turns[from.orientation][0]:
+			b = append(b, turnLeftAction)
+		default:
+			b = append(b, turnRightAction)
+		}
+	}
+	return string(b)
+}
+
+func (m maze) render(path []int) string {
+	g := m.grid.clone()
+	for _, id := range path {
+		node := m.graph.nodes[id]
+		if c := g.get(node.position); c != startChar && c != endChar {
+			g.set(node.position, node.orientation)
+		}
+	}
+	return g.String()
+}
+
+// OptimalPaths yields every lowest scoring route as a sequence of actions
+// starting from the start tile facing east.
+func OptimalPaths(inputs []string, c Costs) (iter.Seq[string], error) {
+	m, err := parseMazeWithCosts(inputs, c)
+	if err != nil {
+		return nil, err
+	}
+	return func(yield func(string) bool) {
+		for path := range m.optimalPaths() {
+			if !yield(m.actions(path)) {
+				return
+			}
+		}
+	}, nil
+}
+
+// RenderOptimalPaths draws each lowest scoring route with arrows showing
+// the direction of travel, up to limit routes.
+func RenderOptimalPaths(inputs []string, c Costs, limit int) (string, error) {
+	m, err := parseMazeWithCosts(inputs, c)
+	if err != nil {
+		return "", err
+	}
+	blocks := []string{}
+	for path := range m.optimalPaths() {
+		if len(blocks) == limit {
+			break
+		}
+		blocks = append(blocks, m.render(path))
+	}
+	return strings.Join(blocks, "\n"), nil
+}
+
+// RenderOptimalTiles marks every tile on some lowest scoring route with O.
+func RenderOptimalTiles(inputs []string, c Costs) (string, error) {
+	m, err := parseMazeWithCosts(inputs, c)
+	if err != nil {
+		return "", err
+	}
+	g := m.grid.clone()
+	for id := range m.optimalStates() {
+		g.set(m.graph.nodes[id].position, tileChar)
+	}
+	return g.String(), nil
+}
@@ -0,0 +1,152 @@
+package day16
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+var example = []string{
+	"###############",
+	"#.......#....E#",
+	"#.#.###.#.###.#",
+	"#.....#.#...#.#",
+	"#.###.#####.#.#",
+	"#.#.#.......#.#",
+	"#.#.#####.###.#",
+	"#...........#.#",
+	"###.#.#####.#.#",
+	"#...#.....#.#.#",
+	"#.#.#.###.#.#.#",
+	"#.....#...#.#.#",
+	"#.###.#.#.#.#.#",
+	"#S..#.....#...#",
+	"###############",
+}
+
+// replay follows actions from the start tile facing east, returning the
+// score and whether every step stays off walls and finishes on the end.
+func replay(inputs []string, c Costs, actions string) (int, bool) {
+	g := parseGrid(inputs)
+	var s state
+	for v, ch := range g.all() {
+		if ch == startChar {
+			s = state{v, rightChar}
+		}
+	}
+	score := 0
+	for i := range len(actions) {
+		switch actions[i] {
+		case forwardAction:
+			step := steps[s.orientation]
+			s.position = vector{s.position.x + step.x, s.position.y + step.y}
+			if g.get(s.position) == wallChar {
+				return score, false
+			}
+			score += c.Move
+		case turnLeftAction:
+			s.orientation = turns[s.orientation][0]
+			score += c.Turn
+		case turnRightAction:
+			s.orientation = turns[s.orientation][1]
+			score += c.Turn
+		}
+	}
+	return score, g.get(s.position) == endChar
+}
+
+func TestOptimalPaths(t *testing.T) {
+	cases := []struct {
+		inputs   []string
+		costs    Costs
+		expected int
+	}{
+		{[]string{"######", "#....#", "###.##", "#E..##", "#.#.##", "#....#", "#.####", "#S..##", "######"}, DefaultCosts, 1},
+		{example, DefaultCosts, 3},
+		{example, Costs{2, 3}, 3},
+		{example, Costs{1, 1}, 3},
+	}
+	for _, c := range cases {
+		seq, err := OptimalPaths(c.inputs, c.costs)
+		if err != nil {
+			t.Errorf("OptimalPaths(%q, %v) failed: %v", c.inputs, c.costs, err)
+			continue
+		}
+		paths := slices.Collect(seq)
+		if len(paths) != c.expected {
+			t.Errorf("len(OptimalPaths(%q, %v)) == %d, expected %d", c.inputs, c.costs, len(paths), c.expected)
+		}
+		best, _ := MinScoreWithCosts(c.inputs, c.costs)
+		for _, path := range paths {
+			if score, ok := replay(c.inputs, c.costs, path); !ok || score != best {
+				t.Errorf("path %q scores %d, reaches end %v, expected score %d", path, score, ok, best)
+			}
+		}
+	}
+}
+
+func TestRenderOptimalTiles(t *testing.T) {
+	for _, costs := range []Costs{DefaultCosts, {1, 1}, {5, 1}} {
+		rendered, _ := RenderOptimalTiles(example, costs)
+		tiles := strings.Count(rendered, string(tileChar))
+		if expected, _ := CountTilesWithCosts(example, costs); tiles != expected {
+			t.Errorf("RenderOptimalTiles(example, %v) marks %d tiles, expected %d", costs, tiles, expected)
+		}
+	}
+}
+
+func TestRenderOptimalPaths(t *testing.T) {
+	inputs := []string{"#####", "#..E#", "#.#.#", "#S..#", "#####"}
+	cases := []struct {
+		costs    Costs
+		expected []string
+	}{
+		{
+			costs: Costs{1, 1},
+			expected: []string{
+				"#####",
+				"#..E#",
+				"#.#^#",
+				"#S>^#",
+				"#####",
+			},
+		},
+		{
+			costs: Costs{1, 1000},
+			expected: []string{
+				"#####",
+				"#..E#",
+				"#.#^#",
+				"#S>^#",
+				"#####",
+			},
+		},
+	}
+	for _, c := range cases {
+		result, _ := RenderOptimalPaths(inputs, c.costs, 5)
+		expected := strings.Join(c.expected, "\n") + "\n"
+		if result != expected {
+			t.Errorf("RenderOptimalPaths(%q, %v) ==\n%s\nexpected\n%s", inputs, c.costs, result, expected)
+		}
+	}
+}
+
+func TestInvalidCosts(t *testing.T) {
+	for _, costs := range []Costs{{0, 1}, {1, 0}, {-1, 1000}, {1, -5}, {}} {
+		if _, err := MinScoreWithCosts(example, costs); err == nil {
+			t.Errorf("MinScoreWithCosts(example, %v) accepted the costs", costs)
+		}
+		if _, err := CountTilesWithCosts(example, costs); err == nil {
+			t.Errorf("CountTilesWithCosts(example, %v) accepted the costs", costs)
+		}
+		if _, err := OptimalPaths(example, costs); err == nil {
+			t.Errorf("OptimalPaths(example, %v) accepted the costs", costs)
+		}
+		if _, err := RenderOptimalPaths(example, costs, 1); err == nil {
+			t.Errorf("RenderOptimalPaths(example, %v) accepted the costs", costs)
+		}
+		if _, err := RenderOptimalTiles(example, costs); err == nil {
+			t.Errorf("RenderOptimalTiles(example, %v) accepted the costs", costs)
+		}
+	}
+}
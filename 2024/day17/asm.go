@@ -0,0 +1,174 @@
+package day17
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var mnemonics = [8]string{"adv", "bxl", "bst", "jnz", "bxc", "out", "bdv", "cdv"}
+
+var comboNames = [8]string{"0", "1", "2", "3", "a", "b", "c", "invalid"}
+
+func usesCombo(opcode uint8) bool {
+	switch opcode {
+	case adv, bst, out, bdv, cdv:
+		return true
+	}
+	return false
+}
+
+func (inst instruction) String() string {
+	switch {
+	case usesCombo(inst.opcode):
+		return fmt.Sprintf("%s %s", mnemonics[inst.opcode], comboNames[inst.operand])
+	case inst.opcode == bxc && inst.operand == 0:
+		return mnemonics[bxc]
+	default:
+		return fmt.Sprintf("%s %d", mnemonics[inst.opcode], inst.operand)
+	}
+}
+
+// disassemble lists each instruction with its address, counted in 3-bit
+// words as jnz targets are.
+func disassemble(program []instruction) []string {
+	lines := make([]string, len(program))
+	for i, inst := range program {
+		lines[i] = fmt.Sprintf("%02d: %s", 2*i, inst)
+	}
+	return lines
+}
+
+var listingPattern = regexp.MustCompile(`^(?:\d+:)?\s*([a-z]{3})(?:\s+([0-9a-z]+))?$`)
+
+func assemble(listing []string) ([]instruction, error) {
+	program := []instruction{}
+	for i, line := range listing {
+		if comment := strings.IndexByte(line, ';'); comment >= 0 {
+			line = line[:comment]
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		match := listingPattern.FindStringSubmatch(line)
+		if match == nil {
+			return nil, fmt.Errorf("line %d: cannot parse %q", i+1, line)
+		}
+		opcode := -1
+		for code, mnemonic := range mnemonics {
+			if mnemonic == match[1] {
+				opcode = code
+			}
+		}
+		if opcode < 0 {
+			return nil, fmt.Errorf("line %d: unknown mnemonic %q", i+1, match[1])
+		}
+		operand := -1
+		if usesCombo(uint8(opcode)) {
+			for code, name := range comboNames[:7] {
+				if name == match[2] {
+					operand = code
+				}
+			}
+		} else if match[2] == "" && opcode == bxc {
+			operand = 0
+		} else if n, err := strconv.Atoi(match[2]); err == nil && n >= 0 && n < 8 {
+			operand = n
+		}
+		if operand < 0 {
+			return nil, fmt.Errorf("line %d: invalid operand %q for %s", i+1, match[2], match[1])
+		}
+		program = append(program, instruction{uint8(opcode), uint8(operand)})
+	}
+	return program, nil
+}
+
+func formatProgram(program []instruction) string {
+	octals := make([]string, 0, 2*len(program))
+	for _, inst := range program {
+		octals = append(octals, strconv.Itoa(int(inst.opcode)), strconv.Itoa(int(inst.operand)))
+	}
+	return "Program: " + strings.Join(octals, ",")
+}
+
+func Disassemble(inputs []string) string {
+	e := parseEmulator[int](inputs)
+	return strings.Join(disassemble(e.program), "\n")
+}
+
+// Assemble turns a listing in the format written by Disassemble back into
+// a Program line. Addresses before a colon and comments after a semicolon
+// are ignored.
+func Assemble(listing []string) (string, error) {
+	program, err := assemble(listing)
+	if err != nil {
+		return "", err
+	}
+	return formatProgram(program), nil
+}
+
+type TraceStep struct {
+	Step, Address int
+	Instruction   string
+	A, B, C       int
+	Output        int
+	HasOutput     bool
+}
+
+func (s TraceStep) String() string {
+	line := fmt.Sprintf("%6d  %02d: %-8s A=%d B=%d C=%d", s.Step, s.Address, s.Instruction, s.A, s.B, s.C)
+	if s.HasOutput {
+		line += fmt.Sprintf(" out=%d", s.Output)
+	}
+	return line
+}
+
+// Tracer executes a program one instruction at a time, recording the
+// registers after every step and pausing before breakpoint addresses.
+type Tracer struct {
+	e           emulator[int]
+	breakpoints map[int]bool
+	Steps       []TraceStep
+}
+
+func NewTracer(inputs []string) *Tracer {
+	return &Tracer{parseEmulator[int](inputs), map[int]bool{}, []TraceStep{}}
+}
+
+func (t *Tracer) SetBreakpoint(address int) {
+	t.breakpoints[address] = true
+}
+
+func (t *Tracer) Halted() bool {
+	return t.e.halted()
+}
+
+func (t *Tracer) Address() int {
+	return 2 * t.e.instPtr
+}
+
+// Run executes until the program halts, maxSteps more instructions have
+// run, or a breakpoint is reached after at least one step. It reports
+// whether it stopped at a breakpoint.
+func (t *Tracer) Run(maxSteps int) bool {
+	for n := 0; n < maxSteps && !t.e.halted(); n++ {
+		if n > 0 && t.breakpoints[t.Address()] {
+			return true
+		}
+		address, inst := t.Address(), t.e.program[t.e.instPtr]
+		value, ok := t.e.step()
+		t.Steps = append(t.Steps, TraceStep{
+			Step:        len(t.Steps),
+			Address:     address,
+			Instruction: inst.String(),
+			A:           t.e.registers[regA],
+			B:           t.e.registers[regB],
+			C:           t.e.registers[regC],
+			Output:      value,
+			HasOutput:   ok,
+		})
+	}
+	return !t.e.halted() && t.breakpoints[t.Address()]
+}
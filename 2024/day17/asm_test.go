@@ -0,0 +1,103 @@
+package day17
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+var quineInputs = []string{
+	"Register A: 2024",
+	"Register B: 0",
+	"Register C: 0",
+	"",
+	"Program: 0,3,5,4,3,0",
+}
+
+func TestDisassemble(t *testing.T) {
+	cases := []struct {
+		program  string
+		expected []string
+	}{
+		{"Program: 0,3,5,4,3,0", []string{"00: adv 3", "02: out a", "04: jnz 0"}},
+		{"Program: 2,4,1,1,7,5,4,0,6,6", []string{"00: bst a", "02: bxl 1", "04: cdv b", "06: bxc", "08: bdv c"}},
+		{"Program: 4,3,5,7", []string{"00: bxc 3", "02: out invalid"}},
+	}
+	for _, c := range cases {
+		inputs := []string{"Register A: 0", "Register B: 0", "Register C: 0", "", c.program}
+		result := Disassemble(inputs)
+		if result != strings.Join(c.expected, "\n") {
+			t.Errorf("Disassemble(%q) == %q, expected %q", c.program, result, c.expected)
+		}
+	}
+}
+
+func TestAssemble(t *testing.T) {
+	cases := []struct {
+		listing  []string
+		expected string
+	}{
+		{[]string{"00: adv 3", "02: out a", "04: jnz 0"}, "Program: 0,3,5,4,3,0"},
+		{[]string{"; loop body", "bst a", "", "bxl 1 ; flip", "bxc", "cdv b"}, "Program: 2,4,1,1,4,0,7,5"},
+	}
+	for _, c := range cases {
+		result, err := Assemble(c.listing)
+		if err != nil || result != c.expected {
+			t.Errorf("Assemble(%q) == %q, %v, expected %q", c.listing, result, err, c.expected)
+		}
+	}
+}
+
+func TestAssembleErrors(t *testing.T) {
+	cases := [][]string{
+		{"foo 1"},
+		{"adv d"},
+		{"bxl 8"},
+		{"out invalid"},
+		{"jnz"},
+	}
+	for _, listing := range cases {
+		if _, err := Assemble(listing); err == nil {
+			t.Errorf("Assemble(%q) succeeded, expected an error", listing)
+		}
+	}
+}
+
+func TestAssembleRoundTrip(t *testing.T) {
+	program := "Program: 2,4,1,1,7,5,1,5,4,3,0,3,5,5,3,0"
+	inputs := []string{"Register A: 0", "Register B: 0", "Register C: 0", "", program}
+	result, err := Assemble(strings.Split(Disassemble(inputs), "\n"))
+	if err != nil || result != program {
+		t.Errorf("Assemble(Disassemble(%q)) == %q, %v", program, result, err)
+	}
+}
+
+func TestTracer(t *testing.T) {
+	tracer := NewTracer(quineInputs)
+	tracer.SetBreakpoint(0)
+	if !tracer.Run(100) {
+		t.Fatalf("Run did not stop at breakpoint")
+	}
+	expected := []TraceStep{
+		{Step: 0, Address: 0, Instruction: "adv 3", A: 253},
+		{Step: 1, Address: 2, Instruction: "out a", A: 253, Output: 5, HasOutput: true},
+		{Step: 2, Address: 4, Instruction: "jnz 0", A: 253},
+	}
+	if !slices.Equal(tracer.Steps, expected) {
+		t.Errorf("Steps == %v, expected %v", tracer.Steps, expected)
+	}
+	outputs := []string{}
+	for !tracer.Halted() {
+		tracer.Run(100)
+	}
+	for _, step := range tracer.Steps {
+		if step.HasOutput {
+			outputs = append(outputs, string(rune('0'+step.Output)))
+		}
+	}
+	result := strings.Join(outputs, ",")
+	expectedOutput := ExecProgram(quineInputs)
+	if result != expectedOutput {
+		t.Errorf("traced output == %q, expected %q", result, expectedOutput)
+	}
+}
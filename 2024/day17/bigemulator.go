@@ -0,0 +1,109 @@
+package day17
+
+import (
+	"fmt"
+	"log"
+	"math/big"
+	"regexp"
+	"strings"
+)
+
+// bigEmulator runs programs with arbitrary-precision registers, so large
+// initial values and shifts by more than 63 bits behave as exact division
+// by a power of two.
+type bigEmulator struct {
+	registers [3]*big.Int
+	program   []instruction
+	instPtr   int
+}
+
+func parseBigEmulator(inputs []string) bigEmulator {
+	matcher := regexp.MustCompile(regPattern)
+	registers := [3]*big.Int{}
+	for i, s := range inputs[:3] {
+		match := matcher.FindStringSubmatch(s)
+		regVal, ok := new(big.Int).SetString(match[2], 10)
+		if !ok {
+			log.Panicf("Could not parse register value from input %q", s)
+		}
+		registers[i] = regVal
+	}
+	program, _ := parseProgram(inputs[4])
+	return bigEmulator{registers: registers, program: program}
+}
+
+func (e *bigEmulator) combo(operand uint8) *big.Int {
+	switch operand {
+	case valA:
+		return e.registers[regA]
+	case valB:
+		return e.registers[regB]
+	case valC:
+		return e.registers[regC]
+	default:
+		return big.NewInt(int64(operand))
+	}
+}
+
+// shift returns A >> n. Shifts wider than A leave zero, matching the
+// native emulator.
+func (e *bigEmulator) shift(n *big.Int) *big.Int {
+	a := e.registers[regA]
+	if !n.IsUint64() || n.Uint64() >= uint64(a.BitLen()) {
+		return new(big.Int)
+	}
+	return new(big.Int).Rsh(a, uint(n.Uint64()))
+}
+
+func lowOctal(x *big.Int) uint8 {
+	words := x.Bits()
+	if len(words) == 0 {
+		return 0
+	}
+	return uint8(words[0] % 8)
+}
+
+func (e *bigEmulator) step() (uint8, bool) {
+	inst := e.program[e.instPtr]
+	e.instPtr++
+	switch inst.opcode {
+	case adv:
+		e.registers[regA] = e.shift(e.combo(inst.operand))
+	case bxl:
+		e.registers[regB] = new(big.Int).Xor(e.registers[regB], big.NewInt(int64(inst.operand)))
+	case bst:
+		e.registers[regB] = big.NewInt(int64(lowOctal(e.combo(inst.operand))))
+	case jnz:
+		if e.registers[regA].Sign() != 0 {
+			e.instPtr = int(inst.operand) / 2
+		}
+	case bxc:
+		e.registers[regB] = new(big.Int).Xor(e.registers[regB], e.registers[regC])
+	case out:
+		return lowOctal(e.combo(inst.operand)), true
+	case bdv:
+		e.registers[regB] = e.shift(e.combo(inst.operand))
+	case cdv:
+		e.registers[regC] = e.shift(e.combo(inst.operand))
+	}
+	return 0, false
+}
+
+func (e *bigEmulator) halted() bool {
+	return e.instPtr >= len(e.program)
+}
+
+func (e *bigEmulator) execute() string {
+	outputs := []string{}
+	for !e.halted() {
+		if value, ok := e.step(); ok {
+			outputs = append(outputs, fmt.Sprintf("%d", value))
+		}
+	}
+	return strings.Join(outputs, ",")
+}
+
+func ExecProgramBig(inputs []string) string {
+	e := parseBigEmulator(inputs)
+	return e.execute()
+}
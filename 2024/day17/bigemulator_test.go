@@ -0,0 +1,108 @@
+package day17
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func TestExecProgramBig(t *testing.T) {
+	cases := []struct {
+		inputs   []string
+		expected string
+	}{
+		{
+			inputs: []string{
+				"Register A: 729",
+				"Register B: 0",
+				"Register C: 0",
+				"",
+				"Program: 0,1,5,4,3,0",
+			},
+			expected: "4,6,3,5,6,3,5,2,1,0",
+		},
+		{
+			inputs: []string{
+				"Register A: 1267650600228229401496703205383",
+				"Register B: 0",
+				"Register C: 0",
+				"",
+				"Program: 5,4,0,3,3,0",
+			},
+			expected: "7,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,2",
+		},
+		{
+			inputs: []string{
+				"Register A: 1267650600228229401496703205383",
+				"Register B: 99",
+				"Register C: 0",
+				"",
+				"Program: 6,5,5,5,0,5,5,4",
+			},
+			expected: "2,1",
+		},
+		{
+			inputs: []string{
+				"Register A: 1267650600228229401496703205383",
+				"Register B: 97",
+				"Register C: 36893488147419103232",
+				"",
+				"Program: 6,5,5,5,0,6,5,4",
+			},
+			expected: "0,0",
+		},
+	}
+	for _, c := range cases {
+		result := ExecProgramBig(c.inputs)
+		if result != c.expected {
+			t.Errorf("ExecProgramBig(%q) == %q, expected %q", c.inputs, result, c.expected)
+		}
+	}
+}
+
+// randomInputs returns a random program without reserved operands and
+// registers small enough for the native emulator.
+func randomInputs(r *rand.Rand) []string {
+	octals := make([]string, 2*(1+r.Intn(8)))
+	for i := range octals {
+		octals[i] = fmt.Sprint(r.Intn(8))
+		if i%2 == 1 && octals[i] == "7" {
+			octals[i] = "3"
+		}
+	}
+	return []string{
+		fmt.Sprintf("Register A: %d", r.Int63n(1<<48)),
+		fmt.Sprintf("Register B: %d", r.Int63n(1<<8)),
+		fmt.Sprintf("Register C: %d", r.Int63n(1<<48)),
+		"",
+		"Program: " + strings.Join(octals, ","),
+	}
+}
+
+func TestBigEmulatorMatchesNative(t *testing.T) {
+	r := rand.New(rand.NewSource(17))
+	for trial := range 500 {
+		inputs := randomInputs(r)
+		native := parseEmulator[int](inputs)
+		wide := parseBigEmulator(inputs)
+		for step := 0; step < 200 && !native.halted(); step++ {
+			nativeValue, nativeOk := native.step()
+			bigValue, bigOk := wide.step()
+			if nativeOk != bigOk || uint8(nativeValue) != bigValue {
+				t.Fatalf("trial %d step %d: output %d, %v != %d, %v", trial, step, nativeValue, nativeOk, bigValue, bigOk)
+			}
+			for reg, value := range native.registers {
+				if !wide.registers[reg].IsInt64() || wide.registers[reg].Int64() != int64(value) {
+					t.Fatalf("trial %d step %d: register %d == %v, expected %d", trial, step, reg, wide.registers[reg], value)
+				}
+			}
+			if native.instPtr != wide.instPtr {
+				t.Fatalf("trial %d step %d: instruction pointer %d != %d", trial, step, wide.instPtr, native.instPtr)
+			}
+		}
+		if native.halted() != wide.halted() {
+			t.Fatalf("trial %d: halted %v != %v", trial, wide.halted(), native.halted())
+		}
+	}
+}
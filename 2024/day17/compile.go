@@ -0,0 +1,81 @@
+package day17
+
+type machine struct {
+	registers [3]int
+	outputs   []uint8
+}
+
+// op executes one instruction and returns the index of the next one.
+type op func(m *machine) int
+
+// compiledProgram is a program whose instructions have been resolved to
+// closures, so running it does no decoding.
+type compiledProgram struct {
+	ops []op
+	m   machine
+}
+
+func compileCombo(operand uint8) func(m *machine) int {
+	switch operand {
+	case valA:
+		return func(m *machine) int { return m.registers[regA] }
+	case valB:
+		return func(m *machine) int { return m.registers[regB] }
+	case valC:
+		return func(m *machine) int { return m.registers[regC] }
+	default:
+		literal := int(operand)
+		return func(m *machine) int { return literal }
+	}
+}
+
+func compileInstruction(inst instruction, next int) op {
+	combo := compileCombo(inst.operand)
+	literal := int(inst.operand)
+	switch inst.opcode {
+	case adv:
+		if inst.operand < valA {
+			return func(m *machine) int { m.registers[regA] >>= literal; return next }
+		}
+		return func(m *machine) int { m.registers[regA] >>= combo(m); return next }
+	case bxl:
+		return func(m *machine) int { m.registers[regB] ^= literal; return next }
+	case bst:
+		return func(m *machine) int { m.registers[regB] = combo(m) % 8; return next }
+	case jnz:
+		target := literal / 2
+		return func(m *machine) int {
+			if m.registers[regA] != 0 {
+				return target
+			}
+			return next
+		}
+	case bxc:
+		return func(m *machine) int { m.registers[regB] ^= m.registers[regC]; return next }
+	case out:
+		return func(m *machine) int { m.outputs = append(m.outputs, uint8(combo(m)%8)); return next }
+	case bdv:
+		return func(m *machine) int { m.registers[regB] = m.registers[regA] >> combo(m); return next }
+	default:
+		return func(m *machine) int { m.registers[regC] = m.registers[regA] >> combo(m); return next }
+	}
+}
+
+func compile(program []instruction) *compiledProgram {
+	ops := make([]op, len(program))
+	for i, inst := range program {
+		ops[i] = compileInstruction(inst, i+1)
+	}
+	return &compiledProgram{ops: ops}
+}
+
+// run executes the program from the given registers. The returned outputs
+// are only valid until the next call.
+func (p *compiledProgram) run(registers [3]int) []uint8 {
+	p.m.registers = registers
+	p.m.outputs = p.m.outputs[:0]
+	for i := 0; i < len(p.ops); {
+		i = p.ops[i](&p.m)
+	}
+	return p.m.outputs
+}
@@ -0,0 +1,73 @@
+package day17
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+// interpret runs the program with the emulator, giving up after maxSteps.
+func interpret(e emulator[int], maxSteps int) ([]uint8, bool) {
+	outputs := []uint8{}
+	for step := 0; step < maxSteps && !e.halted(); step++ {
+		if value, ok := e.step(); ok {
+			outputs = append(outputs, uint8(value))
+		}
+	}
+	return outputs, e.halted()
+}
+
+func TestCompiledMatchesInterpreter(t *testing.T) {
+	r := rand.New(rand.NewSource(38))
+	for trial := 0; trial < 500; {
+		inputs := randomInputs(r)
+		e := parseEmulator[int](inputs)
+		expected, halted := interpret(e, 1000)
+		if !halted {
+			continue
+		}
+		result := compile(e.program).run(e.registers)
+		if !slices.Equal(result, expected) {
+			t.Errorf("compiled %q == %v, expected %v", inputs, result, expected)
+		}
+		trial++
+	}
+}
+
+func TestCompiledReusesBuffer(t *testing.T) {
+	e := parseEmulator[int](quineInputs)
+	p := compile(e.program)
+	first := slices.Clone(p.run([3]int{117440, 0, 0}))
+	second := p.run([3]int{729, 0, 0})
+	if !slices.Equal(first, e.programOctals) {
+		t.Errorf("run(117440) == %v, expected %v", first, e.programOctals)
+	}
+	if !slices.Equal(second, []uint8{3, 3, 1, 0}) {
+		t.Errorf("run(729) == %v, expected %v", second, []uint8{3, 3, 1, 0})
+	}
+}
+
+var benchmarkInputs = []string{
+	"Register A: 0",
+	"Register B: 0",
+	"Register C: 0",
+	"",
+	"Program: 2,4,1,1,7,5,1,5,4,3,0,3,5,5,3,0",
+}
+
+func BenchmarkInterpreter(b *testing.B) {
+	e := parseEmulator[int](benchmarkInputs)
+	for i := range b.N {
+		e.registers = [3]int{i << 20, 0, 0}
+		e.instPtr = 0
+		e.execute()
+	}
+}
+
+func BenchmarkCompiled(b *testing.B) {
+	e := parseEmulator[int](benchmarkInputs)
+	p := compile(e.program)
+	for i := range b.N {
+		p.run([3]int{i << 20, 0, 0})
+	}
+}
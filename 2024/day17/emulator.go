@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"log"
 	"regexp"
-	"slices"
 	"strconv"
 	"strings"
 )
@@ -49,84 +48,56 @@ type emulator[T ~int | ~int32 | ~int64] struct {
 	programOctals []uint8
 }
 
+func (e *emulator[T]) combo(operand uint8) T {
+	switch operand {
+	case valA:
+		return e.registers[regA]
+	case valB:
+		return e.registers[regB]
+	case valC:
+		return e.registers[regC]
+	default:
+		return T(operand)
+	}
+}
+
+// step executes the instruction at the instruction pointer and reports the
+// value it outputs, if any.
+func (e *emulator[T]) step() (T, bool) {
+	inst := e.program[e.instPtr]
+	e.instPtr++
+	switch inst.opcode {
+	case adv:
+		e.registers[regA] >>= e.combo(inst.operand)
+	case bxl:
+		e.registers[regB] ^= T(inst.operand)
+	case bst:
+		e.registers[regB] = e.combo(inst.operand) % 8
+	case jnz:
+		if e.registers[regA] != 0 {
+			e.instPtr = int(inst.operand) / 2
+		}
+	case bxc:
+		e.registers[regB] ^= e.registers[regC]
+	case out:
+		return e.combo(inst.operand) % 8, true
+	case bdv:
+		e.registers[regB] = e.registers[regA] >> e.combo(inst.operand)
+	case cdv:
+		e.registers[regC] = e.registers[regA] >> e.combo(inst.operand)
+	}
+	return 0, false
+}
+
+func (e *emulator[T]) halted() bool {
+	return e.instPtr >= len(e.program)
+}
+
 func (e *emulator[T]) execute() string {
 	outputs := []string{}
-	for e.instPtr < len(e.program) {
-		switch inst := e.program[e.instPtr]; inst.opcode {
-		case adv:
-			switch inst.operand {
-			case valA:
-				e.registers[regA] >>= e.registers[regA]
-			case valB:
-				e.registers[regA] >>= e.registers[regB]
-			case valC:
-				e.registers[regA] >>= e.registers[regC]
-			default:
-				e.registers[regA] >>= inst.operand
-			}
-			e.instPtr++
-		case bxl:
-			e.registers[regB] ^= T(inst.operand)
-			e.instPtr++
-		case bst:
-			switch inst.operand {
-			case valA:
-				e.registers[regB] = e.registers[regA] % 8
-			case valB:
-				e.registers[regB] = e.registers[regB] % 8
-			case valC:
-				e.registers[regB] = e.registers[regC] % 8
-			default:
-				e.registers[regB] = T(inst.operand)
-			}
-			e.instPtr++
-		case jnz:
-			if e.registers[regA] != 0 {
-				e.instPtr = int(inst.operand)
-			} else {
-				e.instPtr++
-			}
-		case bxc:
-			e.registers[regB] ^= e.registers[regC]
-			e.instPtr++
-		case out:
-			var outVal T
-			switch inst.operand {
-			case valA:
-				outVal = e.registers[regA] % 8
-			case valB:
-				outVal = e.registers[regB] % 8
-			case valC:
-				outVal = e.registers[regC] % 8
-			default:
-				outVal = T(inst.operand)
-			}
-			outputs = append(outputs, fmt.Sprintf("%d", outVal))
-			e.instPtr++
-		case bdv:
-			switch inst.operand {
-			case valA:
-				e.registers[regB] = e.registers[regA] >> e.registers[regA]
-			case valB:
-				e.registers[regB] = e.registers[regA] >> e.registers[regB]
-			case valC:
-				e.registers[regB] = e.registers[regA] >> e.registers[regC]
-			default:
-				e.registers[regB] = e.registers[regA] >> inst.operand
-			}
-			e.instPtr++
-		case cdv:
-			switch inst.operand {
-			case valA:
-				e.registers[regC] = e.registers[regA] >> e.registers[regA]
-			case valB:
-				e.registers[regC] = e.registers[regA] >> e.registers[regB]
-			case valC:
-				e.registers[regC] = e.registers[regA] >> e.registers[regC]
-			default:
-				e.registers[regC] = e.registers[regA] >> inst.operand
-			}
-			e.instPtr++
+	for !e.halted() {
+		if value, ok := e.step(); ok {
+			outputs = append(outputs, fmt.Sprintf("%d", value))
 		}
 	}
 	return strings.Join(outputs, ",")
@@ -140,71 +111,7 @@ func (e emulator[T]) String() string {
 	lines = append(lines, "")
 	lines = append(lines, "Program:")
 	lines = append(lines, "")
-	for _, inst := range e.program {
-		switch inst.opcode {
-		case adv:
-			switch inst.operand {
-			case valA:
-				lines = append(lines, "adv valA")
-			case valB:
-				lines = append(lines, "adv valB")
-			case valC:
-				lines = append(lines, "adv valC")
-			default:
-				lines = append(lines, fmt.Sprintf("adv %d", inst.operand))
-			}
-		case bxl:
-			lines = append(lines, fmt.Sprintf("bxl %d", inst.operand))
-		case bst:
-			switch inst.operand {
-			case valA:
-				lines = append(lines, "bst valA")
-			case valB:
-				lines = append(lines, "bst valB")
-			case valC:
-				lines = append(lines, "bst valC")
-			default:
-				lines = append(lines, fmt.Sprintf("bst %d", inst.operand))
-			}
-		case jnz:
-			lines = append(lines, fmt.Sprintf("jnz %d", inst.operand))
-		case bxc:
-			lines = append(lines, "bxc")
-		case out:
-			switch inst.operand {
-			case valA:
-				lines = append(lines, "out valA")
-			case valB:
-				lines = append(lines, "out valB")
-			case valC:
-				lines = append(lines, "out valC")
-			default:
-				lines = append(lines, fmt.Sprintf("out %d", inst.operand))
-			}
-		case bdv:
-			switch inst.operand {
-			case valA:
-				lines = append(lines, "bdv valA")
-			case valB:
-				lines = append(lines, "bdv valB")
-			case valC:
-				lines = append(lines, "bdv valC")
-			default:
-				lines = append(lines, fmt.Sprintf("bdv %d", inst.operand))
-			}
-		case cdv:
-			switch inst.operand {
-			case valA:
-				lines = append(lines, "cdv valA")
-			case valB:
-				lines = append(lines, "cdv valB")
-			case valC:
-				lines = append(lines, "cdv valC")
-			default:
-				lines = append(lines, fmt.Sprintf("cdv %d", inst.operand))
-			}
-		}
-	}
+	lines = append(lines, disassemble(e.program)...)
 	lines = append(lines, "")
 	return strings.Join(lines, "\n")
 }
@@ -220,29 +127,34 @@ func parseEmulator[T ~int | ~int32 | ~int64](inputs []string) emulator[T] {
 			log.Panicf("Could not parse register value from input %q", s)
 		}
 	}
-	matcher = regexp.MustCompile(progPattern)
+	program, programOctals := parseProgram(inputs[4])
+	return emulator[T]{
+		registers:     registers,
+		program:       program,
+		programOctals: programOctals,
+	}
+}
+
+func parseProgram(s string) ([]instruction, []uint8) {
+	matcher := regexp.MustCompile(progPattern)
 	program := []instruction{}
 	programOctals := []uint8{}
-	match := matcher.FindStringSubmatch(inputs[4])
+	match := matcher.FindStringSubmatch(s)
 	split := strings.Split(match[1], ",")
 	for i := 0; i < len(split)-1; i += 2 {
 		opcode, err := strconv.ParseUint(split[i], 10, 3)
 		if err != nil {
-			log.Panicf("Could not parse opcode value from input %q", inputs[4])
+			log.Panicf("Could not parse opcode value from input %q", s)
 		}
 		programOctals = append(programOctals, uint8(opcode))
 		operand, err := strconv.ParseUint(split[i+1], 10, 3)
 		if err != nil {
-			log.Panicf("Could not parse operand value from input %q", inputs[4])
+			log.Panicf("Could not parse operand value from input %q", s)
 		}
 		programOctals = append(programOctals, uint8(operand))
 		program = append(program, instruction{uint8(opcode), uint8(operand)})
 	}
-	return emulator[T]{
-		registers:     registers,
-		program:       program,
-		programOctals: programOctals,
-	}
+	return program, programOctals
 }
 
 func ExecProgram(inputs []string) string {
@@ -252,37 +164,12 @@ func ExecProgram(inputs []string) string {
 }
 
 func FindRegisterAValue(inputs []string) int {
-	e := parseEmulator[int](inputs)
-	outputTable := make([]uint8, 1<<10)
-	for i := range outputTable {
-		e.registers[regA] = i
-		e.instPtr = 0
-		outputTable[i] = e.execute()[0] % 8
-	}
-	reverseLookup := make([][]int, 8)
-	for i, n := range outputTable {
-		reverseLookup[n] = append(reverseLookup[n], i)
+	values, err := FindQuineValues(inputs)
+	if err != nil {
+		log.Panicf("Cannot search for a quine: %v", err)
 	}
-	possibleRegA := slices.Clone(reverseLookup[e.programOctals[0]])
-	for i, output := range e.programOctals[1:] {
-		newPossibleRegA := []int{}
-		for _, lowerRegA := range possibleRegA {
-			for _, upperRegA := range reverseLookup[output] {
-				if lowerRegA>>(3*(i+1)) == upperRegA%(1<<7) {
-					nextOctal := upperRegA >> 7
-					newPossibleRegA = append(newPossibleRegA,
-						lowerRegA^(nextOctal<<(3*i+10)),
-					)
-				}
-			}
-		}
-		possibleRegA = newPossibleRegA
-	}
-	minRegA := possibleRegA[0]
-	for _, n := range possibleRegA[1:] {
-		if n < minRegA {
-			minRegA = n
-		}
+	if len(values) == 0 {
+		log.Panicf("No value of register A reproduces the program")
 	}
-	return minRegA
+	return values[0]
 }
@@ -0,0 +1,129 @@
+package day17
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// loopShape describes a program amenable to the quine search: a body that
+// shifts A right by three bits once and ends with the only jump, back to
+// the start.
+type loopShape struct {
+	outputsPerIteration int
+}
+
+func analyseLoop(program []instruction) (loopShape, error) {
+	shape := loopShape{}
+	if len(program) == 0 {
+		return shape, errors.New("program is empty")
+	}
+	last := program[len(program)-1]
+	if last.opcode != jnz || last.operand != 0 {
+		return shape, errors.New("program does not end with jnz 0")
+	}
+	body := program[:len(program)-1]
+	shifts := 0
+	defined := [3]bool{regA: true}
+	use := func(reg int, addr int) error {
+		if !defined[reg] {
+			return fmt.Errorf("%02d: %s reads register %c before the loop sets it", 2*addr, program[addr], 'A'+reg)
+		}
+		return nil
+	}
+	for addr, inst := range body {
+		if usesCombo(inst.opcode) {
+			switch inst.operand {
+			case valB, valC:
+				if err := use(int(inst.operand-valA), addr); err != nil {
+					return shape, err
+				}
+			case 7:
+				return shape, fmt.Errorf("%02d: %s uses reserved operand 7", 2*addr, inst)
+			}
+		}
+		switch inst.opcode {
+		case adv:
+			if inst.operand != 3 {
+				return shape, fmt.Errorf("%02d: %s does not shift A by three bits", 2*addr, inst)
+			}
+			shifts++
+		case jnz:
+			return shape, fmt.Errorf("%02d: %s jumps inside the loop", 2*addr, inst)
+		case bxl:
+			if err := use(regB, addr); err != nil {
+				return shape, err
+			}
+		case bxc:
+			if err := use(regB, addr); err != nil {
+				return shape, err
+			}
+			if err := use(regC, addr); err != nil {
+				return shape, err
+			}
+		case out:
+			shape.outputsPerIteration++
+		}
+		switch inst.opcode {
+		case bst, bdv:
+			defined[regB] = true
+		case cdv:
+			defined[regC] = true
+		}
+	}
+	if shifts != 1 {
+		return shape, fmt.Errorf("loop shifts A %d times, expected exactly once", shifts)
+	}
+	if shape.outputsPerIteration == 0 {
+		return shape, errors.New("loop produces no output")
+	}
+	return shape, nil
+}
+
+// findQuines builds A one octal digit at a time from the most significant
+// end. Each digit is one loop iteration, so a prefix of digits must
+// reproduce the matching suffix of the program.
+func findQuines(e emulator[int]) ([]int, error) {
+	shape, err := analyseLoop(e.program)
+	if err != nil {
+		return nil, err
+	}
+	perDigit := shape.outputsPerIteration
+	if len(e.programOctals)%perDigit != 0 {
+		return nil, fmt.Errorf("program length %d is not a multiple of %d outputs per iteration", len(e.programOctals), perDigit)
+	}
+	nDigits := len(e.programOctals) / perDigit
+	if 3*nDigits >= 63 {
+		return nil, fmt.Errorf("a quine would need %d bits in register A", 3*nDigits)
+	}
+	p := compile(e.program)
+	registers := e.registers
+	values := []int{}
+	var search func(prefix int, digits int)
+	search = func(prefix int, digits int) {
+		if digits == nDigits {
+			values = append(values, prefix)
+			return
+		}
+		suffix := e.programOctals[len(e.programOctals)-perDigit*(digits+1):]
+		for d := range 8 {
+			a := prefix<<3 | d
+			if a == 0 {
+				continue
+			}
+			registers[regA] = a
+			if slices.Equal(p.run(registers), suffix) {
+				search(a, digits+1)
+			}
+		}
+	}
+	search(0, 0)
+	slices.Sort(values)
+	return values, nil
+}
+
+// FindQuineValues returns every value of register A, in increasing order,
+// for which the program outputs a copy of itself.
+func FindQuineValues(inputs []string) ([]int, error) {
+	return findQuines(parseEmulator[int](inputs))
+}
@@ -0,0 +1,55 @@
+package day17
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestFindQuineValues(t *testing.T) {
+	cases := []struct {
+		program  string
+		expected []int
+	}{
+		{"Program: 0,3,5,4,3,0", []int{117440, 117441, 117442, 117443, 117444, 117445, 117446, 117447}},
+		{"Program: 2,4,5,1,0,3,3,0", []int{}},
+	}
+	for _, c := range cases {
+		inputs := []string{"Register A: 2024", "Register B: 0", "Register C: 0", "", c.program}
+		result, err := FindQuineValues(inputs)
+		if err != nil || !slices.Equal(result, c.expected) {
+			t.Errorf("FindQuineValues(%q) == %v, %v, expected %v", c.program, result, err, c.expected)
+		}
+		for _, a := range result {
+			inputs[0] = "Register A: " + strconv.Itoa(a)
+			output := ExecProgram(inputs)
+			if "Program: "+output != c.program {
+				t.Errorf("ExecProgram with A=%d == %q, expected %q", a, output, c.program)
+			}
+		}
+	}
+}
+
+func TestFindQuineValuesErrors(t *testing.T) {
+	cases := []struct {
+		program string
+		message string
+	}{
+		{"Program: 0,3,5,4", "does not end with jnz 0"},
+		{"Program: 0,3,5,4,3,2", "does not end with jnz 0"},
+		{"Program: 0,2,5,4,3,0", "does not shift A by three bits"},
+		{"Program: 0,3,0,3,5,4,3,0", "shifts A 2 times"},
+		{"Program: 5,4,3,0", "shifts A 0 times"},
+		{"Program: 0,3,5,5,3,0", "reads register B before"},
+		{"Program: 2,4,1,7,0,3,3,0", "produces no output"},
+		{"Program: 0,3,3,0,5,4,3,0", "jumps inside the loop"},
+	}
+	for _, c := range cases {
+		inputs := []string{"Register A: 0", "Register B: 0", "Register C: 0", "", c.program}
+		_, err := FindQuineValues(inputs)
+		if err == nil || !strings.Contains(err.Error(), c.message) {
+			t.Errorf("FindQuineValues(%q) error == %v, expected %q", c.program, err, c.message)
+		}
+	}
+}
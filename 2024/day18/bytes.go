@@ -198,26 +198,30 @@ func parseMaze(inputs []string, nrows, ncols int, nInputs int) maze {
 			}
 		}
 	}
+	startId, ok := nodeIds[vector{0, 0}]
+	if !ok {
+		startId = -1
+	}
+	endId, ok := nodeIds[vector{ncols - 1, nrows - 1}]
+	if !ok {
+		endId = -1
+	}
 	return maze{
 		grid:    gri,
 		graph:   gra,
-		startId: nodeIds[vector{0, 0}],
-		endId:   nodeIds[vector{ncols - 1, nrows - 1}],
+		startId: startId,
+		endId:   endId,
 	}
 }
 
+// CountSteps returns the fewest steps to the exit after nInputs bytes have
+// fallen, or maxInt if there is no path, including when a byte has landed
+// on the start or the exit.
 func CountSteps(inputs []string, nrows, ncols int, nInputs int) int {
 	maze := parseMaze(inputs, nrows, ncols, nInputs)
+	if maze.startId < 0 || maze.endId < 0 {
+		return maxInt
+	}
 	dists, _ := dijkstra(maze.graph, maze.startId)
 	return dists[maze.endId]
 }
-
-func FindFinalInput(inputs []string, nrows, ncols int) string {
-	var i int
-	for i = len(inputs); i >= 1; i-- {
-		if CountSteps(inputs, nrows, ncols, i) != maxInt {
-			break
-		}
-	}
-	return inputs[i]
-}
@@ -42,6 +42,9 @@ func TestCountSteps(t *testing.T) {
 			nInputs:  12,
 			expected: 22,
 		},
+		{[]string{"1,1", "0,0"}, 3, 3, 1, 4},
+		{[]string{"1,1", "0,0"}, 3, 3, 2, maxInt},
+		{[]string{"2,2"}, 3, 3, 1, maxInt},
 	}
 	for _, c := range cases {
 		result := CountSteps(c.inputs, c.nrows, c.ncols, c.nInputs)
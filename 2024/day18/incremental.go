@@ -0,0 +1,226 @@
+package day18
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+)
+
+func parseBytes(inputs []string) []vector {
+	bytes := []vector{}
+	for _, input := range inputs {
+		if input == "" {
+			continue
+		}
+		split := strings.Split(input, ",")
+		if len(split) != 2 {
+			log.Panicf("Could not parse byte position from input %q", input)
+		}
+		x, err := strconv.Atoi(split[0])
+		if err != nil {
+			log.Panicf("Could not parse x from input %q", input)
+		}
+		y, err := strconv.Atoi(split[1])
+		if err != nil {
+			log.Panicf("Could not parse y from input %q", input)
+		}
+		bytes = append(bytes, vector{x, y})
+	}
+	return bytes
+}
+
+// memoryGrid records, for each cell of the memory space, how many bytes
+// have fallen before the first one landing there.
+type memoryGrid struct {
+	nrows, ncols int
+	fallTimes    []int
+}
+
+func newMemoryGrid(bytes []vector, nrows, ncols int) *memoryGrid {
+	m := &memoryGrid{nrows, ncols, make([]int, nrows*ncols)}
+	for i := range m.fallTimes {
+		m.fallTimes[i] = maxInt
+	}
+	for t, b := range bytes {
+		i := m.index(b)
+		m.fallTimes[i] = min(m.fallTimes[i], t)
+	}
+	return m
+}
+
+func (m *memoryGrid) index(v vector) int {
+	return v.y*m.ncols + v.x
+}
+
+func (m *memoryGrid) neighbors(i int) []int {
+	x, y := i%m.ncols, i/m.ncols
+	neighbors := make([]int, 0, 4)
+	if x > 0 {
+		neighbors = append(neighbors, i-1)
+	}
+	if x < m.ncols-1 {
+		neighbors = append(neighbors, i+1)
+	}
+	if y > 0 {
+		neighbors = append(neighbors, i-m.ncols)
+	}
+	if y < m.nrows-1 {
+		neighbors = append(neighbors, i+m.ncols)
+	}
+	return neighbors
+}
+
+// shortestPath runs a breadth-first search once nFallen bytes have landed
+// and returns the cells on one shortest path, or nil if the exit is cut
+// off.
+func (m *memoryGrid) shortestPath(nFallen int) []int {
+	start, end := 0, len(m.fallTimes)-1
+	if m.fallTimes[start] < nFallen || m.fallTimes[end] < nFallen {
+		return nil
+	}
+	prevs := make([]int, len(m.fallTimes))
+	for i := range prevs {
+		prevs[i] = -1
+	}
+	prevs[start] = start
+	queue := []int{start}
+	for len(queue) > 0 && prevs[end] < 0 {
+		u := queue[0]
+		queue = queue[1:]
+		for _, v := range m.neighbors(u) {
+			if prevs[v] < 0 && m.fallTimes[v] >= nFallen {
+				prevs[v] = u
+				queue = append(queue, v)
+			}
+		}
+	}
+	if prevs[end] < 0 {
+		return nil
+	}
+	path := []int{end}
+	for path[len(path)-1] != start {
+		path = append(path, prevs[path[len(path)-1]])
+	}
+	return path
+}
+
+type disjointSet struct {
+	parents []int
+	sizes   []int
+}
+
+func newDisjointSet(n int) *disjointSet {
+	d := &disjointSet{make([]int, n), make([]int, n)}
+	for i := range n {
+		d.parents[i] = i
+		d.sizes[i] = 1
+	}
+	return d
+}
+
+func (d *disjointSet) find(i int) int {
+	for d.parents[i] != i {
+		d.parents[i] = d.parents[d.parents[i]]
+		i = d.parents[i]
+	}
+	return i
+}
+
+func (d *disjointSet) union(i, j int) {
+	i, j = d.find(i), d.find(j)
+	if i == j {
+		return
+	}
+	if d.sizes[i] < d.sizes[j] {
+		i, j = j, i
+	}
+	d.parents[j] = i
+	d.sizes[i] += d.sizes[j]
+}
+
+// firstBlockingByte lets all bytes fall, then lifts them in reverse order,
+// joining each freed cell to its free neighbours until the corners meet.
+// It returns the index of the byte whose removal reconnects them, or -1 if
+// the exit is never cut off. A byte landing on the start or the exit cuts
+// it off like any other, as it does for CountSteps.
+func firstBlockingByte(bytes []vector, nrows, ncols int) int {
+	m := newMemoryGrid(bytes, nrows, ncols)
+	d := newDisjointSet(len(m.fallTimes))
+	start, end := 0, len(m.fallTimes)-1
+	free := func(i int, nFallen int) {
+		for _, j := range m.neighbors(i) {
+			if m.fallTimes[j] >= nFallen {
+				d.union(i, j)
+			}
+		}
+	}
+	for i, t := range m.fallTimes {
+		if t == maxInt {
+			free(i, len(bytes))
+		}
+	}
+	if m.fallTimes[start] == maxInt && m.fallTimes[end] == maxInt && d.find(start) == d.find(end) {
+		return -1
+	}
+	for t := len(bytes) - 1; t >= 0; t-- {
+		i := m.index(bytes[t])
+		if m.fallTimes[i] != t {
+			continue
+		}
+		free(i, t)
+		if m.fallTimes[start] >= t && m.fallTimes[end] >= t && d.find(start) == d.find(end) {
+			return t
+		}
+	}
+	return 0
+}
+
+func FindFinalInput(inputs []string, nrows, ncols int) string {
+	bytes := parseBytes(inputs)
+	t := firstBlockingByte(bytes, nrows, ncols)
+	if t < 0 {
+		log.Panicf("No byte cuts off the exit")
+	}
+	return fmt.Sprintf("%d,%d", bytes[t].x, bytes[t].y)
+}
+
+// Change records that once Bytes bytes have fallen the shortest path to
+// the exit takes Steps steps, or -1 steps when there is none.
+type Change struct {
+	Bytes, Steps int
+}
+
+// PathTimeline lists every point at which the shortest path length
+// changes. A search is only rerun when a byte lands on the current path.
+func PathTimeline(inputs []string, nrows, ncols int) []Change {
+	bytes := parseBytes(inputs)
+	m := newMemoryGrid(bytes, nrows, ncols)
+	path := m.shortestPath(0)
+	timeline := []Change{{0, len(path) - 1}}
+	onPath := make([]bool, len(m.fallTimes))
+	for _, i := range path {
+		onPath[i] = true
+	}
+	for t, b := range bytes {
+		if path == nil {
+			break
+		}
+		i := m.index(b)
+		if !onPath[i] {
+			continue
+		}
+		for _, j := range path {
+			onPath[j] = false
+		}
+		newPath := m.shortestPath(t + 1)
+		for _, j := range newPath {
+			onPath[j] = true
+		}
+		if len(newPath) != len(path) {
+			timeline = append(timeline, Change{t + 1, len(newPath) - 1})
+		}
+		path = newPath
+	}
+	return timeline
+}
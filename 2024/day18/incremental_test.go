@@ -0,0 +1,93 @@
+package day18
+
+import (
+	"fmt"
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+var example = []string{
+	"5,4", "4,2", "4,5", "3,0", "2,1", "6,3", "2,4", "1,5", "0,6", "3,3",
+	"2,6", "5,1", "1,2", "5,5", "2,5", "6,5", "1,4", "0,4", "6,4", "1,1",
+	"6,1", "1,0", "0,5", "1,6", "2,0",
+}
+
+func randomBytes(r *rand.Rand, n, size int) []string {
+	inputs := make([]string, n)
+	for i := range inputs {
+		inputs[i] = fmt.Sprintf("%d,%d", r.Intn(size), r.Intn(size))
+	}
+	return inputs
+}
+
+func TestPathTimeline(t *testing.T) {
+	expected := []Change{{0, 12}, {10, 18}, {12, 22}, {13, 24}, {21, -1}}
+	result := PathTimeline(example, 7, 7)
+	if !slices.Equal(result, expected) {
+		t.Errorf("PathTimeline(example, 7, 7) == %v, expected %v", result, expected)
+	}
+}
+
+func TestPathTimelineMatchesCountSteps(t *testing.T) {
+	r := rand.New(rand.NewSource(18))
+	for range 20 {
+		inputs := randomBytes(r, 60, 9)
+		timeline := PathTimeline(inputs, 9, 9)
+		steps := timeline[0].Steps
+		for n, next := 0, 1; n <= len(inputs); n++ {
+			if next < len(timeline) && timeline[next].Bytes == n {
+				steps = timeline[next].Steps
+				next++
+			}
+			expected := CountSteps(inputs, 9, 9, n)
+			if expected == maxInt {
+				expected = -1
+			}
+			if steps != expected {
+				t.Fatalf("PathTimeline(%q) gives %d steps after %d bytes, expected %d", inputs, steps, n, expected)
+			}
+			if steps < 0 {
+				break
+			}
+		}
+	}
+}
+
+func TestFindFinalInputMatchesCountSteps(t *testing.T) {
+	r := rand.New(rand.NewSource(1024))
+	for range 50 {
+		inputs := randomBytes(r, 80, 9)
+		n := 1
+		for n <= len(inputs) && CountSteps(inputs, 9, 9, n) != maxInt {
+			n++
+		}
+		if n > len(inputs) {
+			continue
+		}
+		result := FindFinalInput(inputs, 9, 9)
+		if result != inputs[n-1] {
+			t.Errorf("FindFinalInput(%q) == %q, expected %q", inputs, result, inputs[n-1])
+		}
+	}
+}
+
+func TestFirstBlockingByte(t *testing.T) {
+	cases := []struct {
+		inputs   []string
+		expected int
+	}{
+		{[]string{"1,1", "1,1", "0,2", ""}, -1},
+		{[]string{"1,1", "0,1", "1,0"}, 2},
+		{[]string{"1,1", "2,2", "0,1"}, 1},
+		{[]string{"0,0", "1,1"}, 0},
+		{[]string{"1,1", "0,0", "0,1"}, 1},
+		{[]string{"1,0", "2,2", "1,1"}, 1},
+	}
+	for _, c := range cases {
+		result := firstBlockingByte(parseBytes(c.inputs), 3, 3)
+		if result != c.expected {
+			t.Errorf("firstBlockingByte(%q) == %d, expected %d", c.inputs, result, c.expected)
+		}
+	}
+}
@@ -1,64 +1,90 @@
 package day19
 
-import "strings"
+import (
+	"aoc2024/memo"
+	"aoc2024/trie"
+	"strings"
+)
 
 type basePatterns struct {
-	patterns []string
+	prefixes *trie.Trie[struct{}]
 }
 
-func (b basePatterns) countPossibles(pattern string) int {
-	possibles := make([]bool, len(pattern)+1)
-	possibles[0] = true
-	matches := make([][]int, len(pattern)+1)
-	counts := make([]int, len(pattern)+1)
-	counts[0] = 1
-	for i := 1; i < len(possibles); i++ {
-		for k, basePattern := range b.patterns {
-			if i-len(basePattern) >= 0 && possibles[i-len(basePattern)] {
-				baseMatch := true
-				for j := 0; j < len(basePattern); j++ {
-					if basePattern[j] != pattern[i-len(basePattern)+j] {
-						baseMatch = false
-						break
-					}
-				}
-				if baseMatch {
-					possibles[i] = true
-					matches[i] = append(matches[i], k)
-				}
-			}
+func newBasePatterns(patterns []string) basePatterns {
+	t := trie.NewTrie[struct{}]()
+	for _, pattern := range patterns {
+		t.Insert(pattern, struct{}{})
+	}
+	return basePatterns{t}
+}
+
+func parsePatterns(inputs []string) (basePatterns, []string) {
+	return newBasePatterns(strings.Split(inputs[0], ", ")), inputs[2:]
+}
+
+func CountPossible(inputs []string) int {
+	count, _ := CountPossibleCached(inputs, 0)
+	return count
+}
+
+func SumCombinations(inputs []string) int {
+	sum, _ := SumCombinationsCached(inputs, 0)
+	return sum
+}
+
+// counter counts the arrangements of a design by splitting off each base
+// pattern it starts with, memoizing the counts of the remaining suffixes
+// across designs.
+type counter struct {
+	cache *memo.Func[string, int]
+}
+
+func newCounter(base basePatterns, capacity int) *counter {
+	return &counter{memo.NewLRU(capacity, func(count func(string) int, design string) int {
+		if design == "" {
+			return 1
 		}
-		for _, k := range matches[i] {
-			counts[i] += counts[i-len(b.patterns[k])]
+		total := 0
+		for n := range base.prefixes.Prefixes(design) {
+			if n > 0 {
+				total += count(design[n:])
+			}
 		}
-	}
-	return counts[len(pattern)]
+		return total
+	})}
 }
 
-func (b basePatterns) isPossible(pattern string) bool {
-	return b.countPossibles(pattern) > 0
+func (c *counter) CountPossibles(design string) int {
+	return c.cache.Call(design)
 }
 
-func parsePatterns(inputs []string) (basePatterns, []string) {
-	return basePatterns{strings.Split(inputs[0], ", ")}, inputs[2:]
+func (c *counter) Stats() memo.Stats {
+	return c.cache.Stats()
 }
 
-func CountPossible(inputs []string) int {
+// CountPossibleCached counts the designs that can be made, sharing one memo
+// of suffix counts between all of them. The memo keeps the capacity most
+// recently used suffixes, or every suffix when capacity is zero.
+func CountPossibleCached(inputs []string, capacity int) (int, memo.Stats) {
 	count := 0
 	base, patterns := parsePatterns(inputs)
+	counter := newCounter(base, capacity)
 	for _, pattern := range patterns {
-		if base.isPossible(pattern) {
+		if counter.CountPossibles(pattern) > 0 {
 			count++
 		}
 	}
-	return count
+	return count, counter.Stats()
 }
 
-func SumCombinations(inputs []string) int {
+// SumCombinationsCached adds up the arrangements of every design with the
+// same shared memo as CountPossibleCached.
+func SumCombinationsCached(inputs []string, capacity int) (int, memo.Stats) {
 	sum := 0
 	base, patterns := parsePatterns(inputs)
+	counter := newCounter(base, capacity)
 	for _, pattern := range patterns {
-		sum += base.countPossibles(pattern)
+		sum += counter.CountPossibles(pattern)
 	}
-	return sum
+	return sum, counter.Stats()
 }
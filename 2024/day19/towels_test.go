@@ -83,3 +83,33 @@ func TestSumCombinations(t *testing.T) {
 		}
 	}
 }
+
+func TestCachedCapacities(t *testing.T) {
+	inputs := []string{
+		"r, wr, b, g, bwu, rb, gb, br",
+		"",
+		"brwrr",
+		"bggr",
+		"gbbr",
+		"rrbgbr",
+		"ubwu",
+		"bwurrg",
+		"brgr",
+		"bbrgwb",
+	}
+	for _, capacity := range []int{0, 1, 4} {
+		if result, _ := CountPossibleCached(inputs, capacity); result != 6 {
+			t.Errorf("CountPossibleCached(%q, %d) == %d, expected 6", inputs, capacity, result)
+		}
+		result, stats := SumCombinationsCached(inputs, capacity)
+		if result != 16 {
+			t.Errorf("SumCombinationsCached(%q, %d) == %d, expected 16", inputs, capacity, result)
+		}
+		if capacity > 0 && stats.Size > capacity {
+			t.Errorf("SumCombinationsCached(%q, %d) kept %d entries", inputs, capacity, stats.Size)
+		}
+	}
+	if _, stats := SumCombinationsCached(inputs, 0); stats.Hits == 0 || stats.Evictions != 0 {
+		t.Errorf("SumCombinationsCached(%q, 0) stats == %v, expected hits and no evictions", inputs, stats)
+	}
+}
@@ -2,17 +2,24 @@ package day20
 
 import (
 	"aoc2024/set"
+	"cmp"
+	"fmt"
 	"iter"
+	"slices"
+	"strings"
 )
 
 const (
-	emptyChar = '.'
-	wallChar  = '#'
-	startChar = 'S'
-	endChar   = 'E'
-	maxUint   = ^uint(0)
-	maxInt    = int(maxUint >> 1)
-	threshold = 100
+	emptyChar      = '.'
+	wallChar       = '#'
+	startChar      = 'S'
+	endChar        = 'E'
+	routeChar      = '*'
+	cheatStartChar = '1'
+	cheatEndChar   = '2'
+	maxUint        = ^uint(0)
+	maxInt         = int(maxUint >> 1)
+	threshold      = 100
 )
 
 type vector struct {
@@ -157,28 +164,10 @@ func dijkstra[T any](g *graph[T], startId int) ([]int, [][]int) {
 	return dists, prevs
 }
 
-type endpoint struct {
-	id                int
-	position          vector
-	distanceFromStart int
-	distanceToEnd     int
-}
-
-type bridge struct {
-	endpoints [2]endpoint
-	cost      int
-}
-
-func (b bridge) getDistanceThrough() int {
-	return min(
-		b.endpoints[0].distanceFromStart+b.endpoints[1].distanceToEnd,
-		b.endpoints[1].distanceFromStart+b.endpoints[0].distanceToEnd,
-	) + b.cost
-}
-
 type maze struct {
 	grid           *grid
 	graph          *graph[vector]
+	nodeIds        map[vector]int
 	startId, endId int
 }
 
@@ -230,49 +219,112 @@ func parseMaze(inputs []string) maze {
 	return maze{
 		grid:    gri,
 		graph:   gra,
+		nodeIds: nodeIds,
 		startId: startId,
 		endId:   endId,
 	}
 }
 
-func countCheatsBySavings(inputs []string, maxCost int, threshold int) map[int]int {
-	var cheat bridge
-	cheatsBySavings := map[int]int{}
-	m := parseMaze(inputs)
-	startDists, _ := dijkstra(m.graph, m.startId)
-	endDists, _ := dijkstra(m.graph, m.endId)
-	baseline := startDists[m.endId]
-	for i, vi := range m.graph.allNodes() {
-		ei := endpoint{
-			id:       i,
-			position: vi,
+type Point struct {
+	X, Y int
+}
+
+// Cheat passes through walls from Start to End, both on the track.
+type Cheat struct {
+	Start, End Point
+	Savings    int
+}
+
+// cheats yields every cheat of at most maxCost moves saving at least
+// threshold, scanning the diamond of cells within maxCost of each track
+// cell. Distances come from both ends of the maze, so any track layout
+// works, not only a single corridor.
+func (m maze) cheats(maxCost int, threshold int) iter.Seq[Cheat] {
+	return func(yield func(Cheat) bool) {
+		startDists, _ := dijkstra(m.graph, m.startId)
+		endDists, _ := dijkstra(m.graph, m.endId)
+		baseline := startDists[m.endId]
+		if baseline == maxInt {
+			return
 		}
-		for j, vj := range m.graph.allNodes() {
-			if j < i {
-				ej := endpoint{
-					id:       j,
-					position: vj,
-				}
-				cheat = bridge{
-					endpoints: [2]endpoint{ei, ej},
-				}
-				for k := range cheat.endpoints {
-					cheat.endpoints[k].distanceFromStart = startDists[cheat.endpoints[k].id]
-					cheat.endpoints[k].distanceToEnd = endDists[cheat.endpoints[k].id]
-				}
-				cheat.cost = dist(cheat.endpoints[0].position, cheat.endpoints[1].position)
-				if cheat.cost <= maxCost {
-					savings := baseline - cheat.getDistanceThrough()
+		for i, vi := range m.graph.allNodes() {
+			if startDists[i] == maxInt {
+				continue
+			}
+			for dy := -maxCost; dy <= maxCost; dy++ {
+				reach := maxCost - max(dy, -dy)
+				for dx := -reach; dx <= reach; dx++ {
+					vj := vector{vi.x + dx, vi.y + dy}
+					j, ok := m.nodeIds[vj]
+					if !ok || endDists[j] == maxInt {
+						continue
+					}
+					savings := baseline - (startDists[i] + dist(vi, vj) + endDists[j])
 					if savings >= threshold {
-						cheatsBySavings[savings]++
+						cheat := Cheat{Point{vi.x, vi.y}, Point{vj.x, vj.y}, savings}
+						if !yield(cheat) {
+							return
+						}
 					}
 				}
 			}
 		}
 	}
+}
+
+func countCheatsBySavings(inputs []string, maxCost int, threshold int) map[int]int {
+	cheatsBySavings := map[int]int{}
+	m := parseMaze(inputs)
+	for cheat := range m.cheats(maxCost, threshold) {
+		cheatsBySavings[cheat.Savings]++
+	}
 	return cheatsBySavings
 }
 
+// FindCheats lists cheats from the largest savings down, breaking ties by
+// start then end position in reading order.
+func FindCheats(inputs []string, maxCost int, threshold int) []Cheat {
+	m := parseMaze(inputs)
+	cheats := slices.Collect(m.cheats(maxCost, threshold))
+	slices.SortFunc(cheats, func(a, b Cheat) int {
+		return cmp.Or(
+			cmp.Compare(b.Savings, a.Savings),
+			cmp.Compare(a.Start.Y, b.Start.Y),
+			cmp.Compare(a.Start.X, b.Start.X),
+			cmp.Compare(a.End.Y, b.End.Y),
+			cmp.Compare(a.End.X, b.End.X),
+		)
+	})
+	return cheats
+}
+
+// RenderCheats draws the n best cheats, each on its own copy of the maze
+// with 1 at the start, 2 at the end and the route between them as *. It
+// draws nothing when n is not positive.
+func RenderCheats(inputs []string, maxCost int, n int) string {
+	cheats := FindCheats(inputs, maxCost, 1)
+	blocks := []string{}
+	for _, cheat := range cheats[:max(0, min(n, len(cheats)))] {
+		g := parseGrid(inputs)
+		v := vector{cheat.Start.X, cheat.Start.Y}
+		end := vector{cheat.End.X, cheat.End.Y}
+		for v != end {
+			if v.x != end.x {
+				v.x += cmp.Compare(end.x, v.x)
+			} else {
+				v.y += cmp.Compare(end.y, v.y)
+			}
+			g.set(v, routeChar)
+		}
+		g.set(vector{cheat.Start.X, cheat.Start.Y}, cheatStartChar)
+		g.set(end, cheatEndChar)
+		header := fmt.Sprintf("Cheat from (%d, %d) to (%d, %d) saves %d picoseconds:\n",
+			cheat.Start.X, cheat.Start.Y, cheat.End.X, cheat.End.Y, cheat.Savings)
+		blocks = append(blocks, header+g.String())
+	}
+	return strings.Join(blocks, "\n")
+}
+
 func CountCheats(inputs []string, maxCost int, threshold int) int {
 	cheatsBySavings := countCheatsBySavings(inputs, maxCost, threshold)
 	sum := 0
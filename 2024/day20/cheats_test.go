@@ -1,6 +1,10 @@
 package day20
 
-import "testing"
+import (
+	"slices"
+	"strings"
+	"testing"
+)
 
 func TestCountCheatsBySavings(t *testing.T) {
 	cases := []struct {
@@ -98,3 +102,119 @@ func TestCountCheatsBySavings(t *testing.T) {
 		}
 	}
 }
+
+var example = []string{
+	"###############",
+	"#...#...#.....#",
+	"#.#.#.#.#.###.#",
+	"#S#...#.#.#...#",
+	"#######.#.#.###",
+	"#######.#.#...#",
+	"#######.#.###.#",
+	"###..E#...#...#",
+	"###.#######.###",
+	"#...###...#...#",
+	"#.#####.#.###.#",
+	"#.#...#.#.#...#",
+	"#.#.#.#.#.#.###",
+	"#...#...#...###",
+	"###############",
+}
+
+var branching = []string{
+	"###########",
+	"#S....#...#",
+	"#.###.#.#.#",
+	"#.#.....#.#",
+	"#.#.###.#.#",
+	"#...#.....#",
+	"###.#.###.#",
+	"#.....#..E#",
+	"###########",
+}
+
+func TestFindCheats(t *testing.T) {
+	expected := []Cheat{
+		{Point{7, 7}, Point{5, 7}, 64},
+		{Point{7, 7}, Point{7, 9}, 40},
+		{Point{8, 7}, Point{8, 9}, 38},
+		{Point{9, 7}, Point{9, 9}, 36},
+		{Point{9, 7}, Point{11, 7}, 20},
+	}
+	result := FindCheats(example, 2, 20)
+	if !slices.Equal(result, expected) {
+		t.Errorf("FindCheats(example, 2, 20) == %v, expected %v", result, expected)
+	}
+	best := FindCheats(example, 20, 76)
+	if len(best) != 3 || best[0] != (Cheat{Point{1, 3}, Point{3, 7}, 76}) {
+		t.Errorf("FindCheats(example, 20, 76) == %v", best)
+	}
+}
+
+// bruteForceCheats checks every ordered pair of track cells.
+func bruteForceCheats(inputs []string, maxCost int, threshold int) map[Cheat]bool {
+	m := parseMaze(inputs)
+	startDists, _ := dijkstra(m.graph, m.startId)
+	endDists, _ := dijkstra(m.graph, m.endId)
+	cheats := map[Cheat]bool{}
+	for i, vi := range m.graph.allNodes() {
+		for j, vj := range m.graph.allNodes() {
+			if startDists[i] == maxInt || endDists[j] == maxInt || dist(vi, vj) > maxCost {
+				continue
+			}
+			savings := startDists[m.endId] - (startDists[i] + dist(vi, vj) + endDists[j])
+			if savings >= threshold {
+				cheats[Cheat{Point{vi.x, vi.y}, Point{vj.x, vj.y}, savings}] = true
+			}
+		}
+	}
+	return cheats
+}
+
+func TestCheatsMatchBruteForce(t *testing.T) {
+	for _, inputs := range [][]string{example, branching} {
+		for _, maxCost := range []int{2, 3, 6, 20} {
+			expected := bruteForceCheats(inputs, maxCost, 1)
+			result := FindCheats(inputs, maxCost, 1)
+			if len(result) != len(expected) {
+				t.Errorf("len(FindCheats(\n%s, %d, 1)) == %d, expected %d", parseGrid(inputs), maxCost, len(result), len(expected))
+			}
+			for _, cheat := range result {
+				if !expected[cheat] {
+					t.Errorf("FindCheats(\n%s, %d, 1) found unexpected %v", parseGrid(inputs), maxCost, cheat)
+				}
+			}
+		}
+	}
+}
+
+func TestRenderCheats(t *testing.T) {
+	expected := []string{
+		"Cheat from (7, 7) to (5, 7) saves 64 picoseconds:",
+		"###############",
+		"#...#...#.....#",
+		"#.#.#.#.#.###.#",
+		"#S#...#.#.#...#",
+		"#######.#.#.###",
+		"#######.#.#...#",
+		"#######.#.###.#",
+		"###..2*1..#...#",
+		"###.#######.###",
+		"#...###...#...#",
+		"#.#####.#.###.#",
+		"#.#...#.#.#...#",
+		"#.#.#.#.#.#.###",
+		"#...#...#...###",
+		"###############",
+		"",
+	}
+	result := RenderCheats(example, 2, 1)
+	if result != strings.Join(expected, "\n") {
+		t.Errorf("RenderCheats(example, 2, 1) ==\n%s\nexpected\n%s", result, strings.Join(expected, "\n"))
+	}
+	for _, n := range []int{0, -1} {
+		if result := RenderCheats(example, 2, n); result != "" {
+			t.Errorf("RenderCheats(example, 2, %d) ==\n%s\nexpected nothing", n, result)
+		}
+	}
+}
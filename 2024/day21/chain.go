@@ -0,0 +1,295 @@
+package day21
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+)
+
+const directions = "^v<>A"
+
+var moves = map[byte]vector{
+	'^': {0, -1},
+	'v': {0, 1},
+	'<': {-1, 0},
+	'>': {1, 0},
+}
+
+// chain is a sequence of keypads where the code is typed on the first one
+// and each later keypad drives the robot arm over the one before it. The
+// last keypad is pressed directly.
+type chain struct {
+	pads []keyPad
+	// costs[i][[from, to]] is the number of direct presses needed to move
+	// the arm over pads[i] from one key to another and press it.
+	costs []map[[2]byte]int
+	// best[i][[from, to]] is the press sequence on pads[i+1] achieving it.
+	best []map[[2]byte]string
+}
+
+func newChain(pads []keyPad) (*chain, error) {
+	if len(pads) == 0 {
+		return nil, errors.New("chain has no keypads")
+	}
+	for i, k := range pads[1:] {
+		for _, c := range []byte(directions) {
+			if _, ok := k.keyMap[c]; !ok {
+				return nil, fmt.Errorf("keypad %d drives a robot but has no %q key", i+1, c)
+			}
+		}
+	}
+	c := &chain{
+		pads:  pads,
+		costs: make([]map[[2]byte]int, len(pads)),
+		best:  make([]map[[2]byte]string, len(pads)),
+	}
+	last := len(pads) - 1
+	c.costs[last] = map[[2]byte]int{}
+	for from := range pads[last].keyMap {
+		for to := range pads[last].keyMap {
+			c.costs[last][[2]byte{from, to}] = 1
+		}
+	}
+	for i := last - 1; i >= 0; i-- {
+		c.costs[i] = map[[2]byte]int{}
+		c.best[i] = map[[2]byte]string{}
+		for from := range pads[i].keyMap {
+			costs, best := c.cheapestPresses(i, from)
+			for to := range pads[i].keyMap {
+				if _, ok := costs[to]; !ok {
+					return nil, fmt.Errorf("keypad %d cannot move from %q to %q", i, from, to)
+				}
+				c.costs[i][[2]byte{from, to}] = costs[to]
+				c.best[i][[2]byte{from, to}] = best[to]
+			}
+		}
+	}
+	return c, nil
+}
+
+// armState is the key under the arm over a keypad together with the key
+// last pressed on the keypad driving it.
+type armState struct {
+	key, last byte
+}
+
+// cheapestPresses finds, for each key on pads[level], the cheapest press
+// sequence on pads[level+1] moving the arm there from the from key and
+// pressing it. It runs Dijkstra over arm states, so routes may turn as often
+// as needed to get around gaps. Ties go to the lexicographically smallest
+// sequence.
+func (c *chain) cheapestPresses(level int, from byte) (map[byte]int, map[byte]string) {
+	type label struct {
+		cost int
+		seq  string
+	}
+	less := func(a, b label) bool {
+		return a.cost < b.cost || a.cost == b.cost && a.seq < b.seq
+	}
+	pad, driver := c.pads[level], c.costs[level+1]
+	labels := map[armState]label{{from, 'A'}: {0, ""}}
+	settled := map[armState]bool{}
+	costs, best := map[byte]int{}, map[byte]string{}
+	for {
+		// Keypads are small, so scanning for the next state is cheaper than
+		// keeping a priority queue.
+		var current armState
+		found := false
+		for s, l := range labels {
+			if !settled[s] && (!found || less(l, labels[current])) {
+				current, found = s, true
+			}
+		}
+		if !found {
+			return costs, best
+		}
+		settled[current] = true
+		l := labels[current]
+
+		pressed := label{l.cost + driver[[2]byte{current.last, 'A'}], l.seq + "A"}
+		if old, ok := costs[current.key]; !ok || less(pressed, label{old, best[current.key]}) {
+			costs[current.key], best[current.key] = pressed.cost, pressed.seq
+		}
+		position := pad.keyMap[current.key]
+		for _, d := range []byte("^v<>") {
+			next, ok := pad.layout.get(vector{position.x + moves[d].x, position.y + moves[d].y})
+			if !ok || next == 0 {
+				continue
+			}
+			s := armState{next, d}
+			moved := label{l.cost + driver[[2]byte{current.last, d}], l.seq + string(d)}
+			if old, ok := labels[s]; settled[s] || ok && !less(moved, old) {
+				continue
+			}
+			labels[s] = moved
+		}
+	}
+}
+
+// newKeyPadChain builds the puzzle's chain of one numeric keypad followed
+// by nDirectional directional keypads.
+func newKeyPadChain(nDirectional int) *chain {
+	pads := []keyPad{mustParseKeyPad(NumericLayout)}
+	for range nDirectional {
+		pads = append(pads, mustParseKeyPad(DirectionalLayout))
+	}
+	c, err := newChain(pads)
+	if err != nil {
+		log.Panicf("Could not build keypad chain: %v", err)
+	}
+	return c
+}
+
+// sequenceCost is the number of direct presses needed to type seq on
+// pads[level], starting and ending with its arm on A.
+func (c *chain) sequenceCost(level int, seq string) int {
+	cost := 0
+	var from byte = 'A'
+	for i := range len(seq) {
+		cost += c.costs[level][[2]byte{from, seq[i]}]
+		from = seq[i]
+	}
+	return cost
+}
+
+func (c *chain) checkCode(code string) error {
+	for i := range len(code) {
+		if _, ok := c.pads[0].keyMap[code[i]]; !ok {
+			return fmt.Errorf("code %q has key %q missing from keypad 0", code, code[i])
+		}
+	}
+	return nil
+}
+
+func (c *chain) shortestLength(code string) int {
+	return c.sequenceCost(0, code)
+}
+
+// shortestPresses reconstructs one optimal sequence of direct presses for
+// code. Its length grows exponentially with the number of keypads.
+func (c *chain) shortestPresses(code string) string {
+	var b strings.Builder
+	var expand func(level int, seq string)
+	expand = func(level int, seq string) {
+		if level == len(c.pads)-1 {
+			b.WriteString(seq)
+			return
+		}
+		var from byte = 'A'
+		for i := range len(seq) {
+			expand(level+1, c.best[level][[2]byte{from, seq[i]}])
+			from = seq[i]
+		}
+	}
+	expand(0, code)
+	return b.String()
+}
+
+// simulate replays direct presses on the last keypad through the chain and
+// returns what is typed on the first one.
+func (c *chain) simulate(presses string) (string, error) {
+	arms := make([]vector, len(c.pads))
+	for i, k := range c.pads {
+		arms[i] = k.keyMap['A']
+	}
+	typed := []byte{}
+	for n := range len(presses) {
+		key := presses[n]
+		level := len(c.pads) - 1
+		if _, ok := c.pads[level].keyMap[key]; !ok {
+			return "", fmt.Errorf("press %d: no key %q on keypad %d", n, key, level)
+		}
+		for level > 0 && key == 'A' {
+			level--
+			key, _ = c.pads[level].layout.get(arms[level])
+		}
+		if level == 0 {
+			typed = append(typed, key)
+			continue
+		}
+		arm := arms[level-1]
+		arm.x += moves[key].x
+		arm.y += moves[key].y
+		if k, ok := c.pads[level-1].layout.get(arm); !ok || k == 0 {
+			return "", fmt.Errorf("press %d: arm over keypad %d leaves the keys", n, level-1)
+		}
+		arms[level-1] = arm
+	}
+	return string(typed), nil
+}
+
+// ParseLayouts splits keypad layouts separated by blank lines, listed from
+// the keypad the code is typed on to the one pressed directly.
+func ParseLayouts(inputs []string) [][]string {
+	layouts := [][]string{}
+	layout := []string{}
+	for _, line := range inputs {
+		if strings.TrimSpace(line) == "" {
+			if len(layout) > 0 {
+				layouts = append(layouts, layout)
+			}
+			layout = []string{}
+			continue
+		}
+		layout = append(layout, line)
+	}
+	if len(layout) > 0 {
+		layouts = append(layouts, layout)
+	}
+	return layouts
+}
+
+func parseChain(layouts [][]string) (*chain, error) {
+	pads := make([]keyPad, len(layouts))
+	for i, layout := range layouts {
+		k, err := parseKeyPad(layout)
+		if err != nil {
+			return nil, fmt.Errorf("keypad %d: %w", i, err)
+		}
+		pads[i] = k
+	}
+	return newChain(pads)
+}
+
+// CalcChainComplexity is CalcComplexity for an arbitrary chain of keypad
+// layouts.
+func CalcChainComplexity(inputs []string, layouts [][]string) (int, error) {
+	c, err := parseChain(layouts)
+	if err != nil {
+		return 0, err
+	}
+	sum := 0
+	for _, input := range inputs {
+		if err := c.checkCode(input); err != nil {
+			return 0, err
+		}
+		sum += getNumericPart(input) * c.shortestLength(input)
+	}
+	return sum, nil
+}
+
+// FindPresses returns an optimal sequence of direct presses for each code,
+// checked by replaying it through the chain.
+func FindPresses(inputs []string, layouts [][]string) ([]string, error) {
+	c, err := parseChain(layouts)
+	if err != nil {
+		return nil, err
+	}
+	presses := make([]string, len(inputs))
+	for i, input := range inputs {
+		if err := c.checkCode(input); err != nil {
+			return nil, err
+		}
+		presses[i] = c.shortestPresses(input)
+		if typed, err := c.simulate(presses[i]); err != nil {
+			return nil, fmt.Errorf("replaying presses for %q: %w", input, err)
+		} else if typed != input {
+			return nil, fmt.Errorf("presses for %q type %q", input, typed)
+		}
+		if len(presses[i]) != c.shortestLength(input) {
+			return nil, fmt.Errorf("presses for %q have length %d, expected %d", input, len(presses[i]), c.shortestLength(input))
+		}
+	}
+	return presses, nil
+}
@@ -0,0 +1,229 @@
+package day21
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+)
+
+var exampleCodes = []string{"029A", "980A", "179A", "456A", "379A"}
+
+func TestParseKeyPad(t *testing.T) {
+	cases := []struct {
+		lines   []string
+		message string
+	}{
+		{[]string{}, "empty"},
+		{[]string{"12", "21"}, "more than once"},
+		{[]string{"12", "3"}, "no A key"},
+	}
+	for _, c := range cases {
+		_, err := parseKeyPad(c.lines)
+		if err == nil || !strings.Contains(err.Error(), c.message) {
+			t.Errorf("parseKeyPad(%q) error == %v, expected %q", c.lines, err, c.message)
+		}
+	}
+	k := mustParseKeyPad(NumericLayout)
+	if v := k.keyMap['0']; v != (vector{1, 3}) {
+		t.Errorf("key 0 at %v, expected %v", v, vector{1, 3})
+	}
+	if c, _ := k.layout.get(vector{0, 3}); c != 0 {
+		t.Errorf("gap holds key %q", c)
+	}
+}
+
+func TestParseLayouts(t *testing.T) {
+	inputs := []string{"789", "456", "123", " 0A", "", " ^A", "<v>", "", "", " ^A", "<v>"}
+	expected := [][]string{NumericLayout, DirectionalLayout, DirectionalLayout}
+	result := ParseLayouts(inputs)
+	if !slices.EqualFunc(result, expected, slices.Equal) {
+		t.Errorf("ParseLayouts(%q) == %q, expected %q", inputs, result, expected)
+	}
+}
+
+func TestCalcChainComplexity(t *testing.T) {
+	cases := []struct {
+		layouts  [][]string
+		expected int
+	}{
+		{[][]string{NumericLayout, DirectionalLayout, DirectionalLayout, DirectionalLayout}, 126384},
+		{[][]string{NumericLayout}, 4 * (29 + 980 + 179 + 456 + 379)},
+	}
+	for _, c := range cases {
+		result, err := CalcChainComplexity(exampleCodes, c.layouts)
+		if err != nil || result != c.expected {
+			t.Errorf("CalcChainComplexity(%q, %q) == %d, %v, expected %d", exampleCodes, c.layouts, result, err, c.expected)
+		}
+	}
+	if _, err := CalcChainComplexity(exampleCodes, [][]string{NumericLayout, NumericLayout}); err == nil {
+		t.Errorf("CalcChainComplexity accepted a numeric keypad driving a robot")
+	}
+}
+
+func TestFindPresses(t *testing.T) {
+	chains := [][][]string{
+		{NumericLayout, DirectionalLayout},
+		{NumericLayout, DirectionalLayout, DirectionalLayout, DirectionalLayout},
+		{NumericLayout, {"<^>", " vA"}, DirectionalLayout, {"A^", "<v>"}},
+		{{"0123456789A"}, DirectionalLayout, DirectionalLayout},
+	}
+	for _, layouts := range chains {
+		c, err := parseChain(layouts)
+		if err != nil {
+			t.Fatalf("parseChain(%q) failed: %v", layouts, err)
+		}
+		presses, err := FindPresses(exampleCodes, layouts)
+		if err != nil {
+			t.Errorf("FindPresses(%q, %q) failed: %v", exampleCodes, layouts, err)
+			continue
+		}
+		for i, code := range exampleCodes {
+			if len(presses[i]) != c.shortestLength(code) {
+				t.Errorf("presses %q for %q have length %d, expected %d", presses[i], code, len(presses[i]), c.shortestLength(code))
+			}
+		}
+	}
+	presses, _ := FindPresses([]string{"029A"}, [][]string{NumericLayout, DirectionalLayout})
+	if len(presses[0]) != 12 {
+		t.Errorf("FindPresses(029A) == %q, expected 12 presses", presses[0])
+	}
+}
+
+func TestSimulate(t *testing.T) {
+	c := newKeyPadChain(3)
+	cases := []struct {
+		presses  string
+		expected string
+		message  string
+	}{
+		{"<vA<AA>>^AvAA<^A>A<v<A>>^AvA^A<vA>^A<v<A>^A>AAvA^A<v<A>A>^AAAvA<^A>A", "029A", ""},
+		{"", "", ""},
+		{"A", "A", ""},
+		{"<<", "", "leaves the keys"},
+		{"<A", "", "leaves the keys"},
+		{"x", "", "no key"},
+	}
+	for _, tc := range cases {
+		result, err := c.simulate(tc.presses)
+		if tc.message == "" && (err != nil || result != tc.expected) {
+			t.Errorf("simulate(%q) == %q, %v, expected %q", tc.presses, result, err, tc.expected)
+		}
+		if tc.message != "" && (err == nil || !strings.Contains(err.Error(), tc.message)) {
+			t.Errorf("simulate(%q) error == %v, expected %q", tc.presses, err, tc.message)
+		}
+	}
+}
+
+// bruteForceLength searches over the arm positions of every keypad for the
+// fewest direct presses typing code.
+func bruteForceLength(c *chain, code string) int {
+	type state struct {
+		arms  string
+		typed int
+	}
+	encode := func(arms []vector) string {
+		return fmt.Sprint(arms)
+	}
+	start := make([]vector, len(c.pads))
+	for i, k := range c.pads {
+		start[i] = k.keyMap['A']
+	}
+	arms := map[string][]vector{encode(start): start}
+	frontier := []state{{encode(start), 0}}
+	seen := map[state]bool{frontier[0]: true}
+	last := len(c.pads) - 1
+	for presses := 1; len(frontier) > 0; presses++ {
+		next := []state{}
+		for _, s := range frontier {
+			for key := range c.pads[last].keyMap {
+				current := slices.Clone(arms[s.arms])
+				typed := s.typed
+				level := last
+				for level > 0 && key == 'A' {
+					level--
+					key, _ = c.pads[level].layout.get(current[level])
+				}
+				if level == 0 {
+					if key != code[typed] {
+						continue
+					}
+					if typed++; typed == len(code) {
+						return presses
+					}
+				} else {
+					arm := current[level-1]
+					arm.x += moves[key].x
+					arm.y += moves[key].y
+					if k, ok := c.pads[level-1].layout.get(arm); !ok || k == 0 {
+						continue
+					}
+					current[level-1] = arm
+				}
+				ns := state{encode(current), typed}
+				if !seen[ns] {
+					seen[ns] = true
+					arms[ns.arms] = current
+					next = append(next, ns)
+				}
+			}
+		}
+		frontier = next
+	}
+	return -1
+}
+
+func TestChainAroundGaps(t *testing.T) {
+	winding := []string{"A 2", "345"}
+	cases := []struct {
+		layouts [][]string
+		codes   []string
+	}{
+		{[][]string{winding, DirectionalLayout}, []string{"2A", "52A", "3A"}},
+		{[][]string{winding, DirectionalLayout, DirectionalLayout}, []string{"2A", "52A", "3A"}},
+		{[][]string{NumericLayout, {"^ A", "<v>"}, DirectionalLayout}, []string{"029A", "179A"}},
+		{[][]string{{"1 A", "2 3", "456"}, {"A^ ", "<v>"}, DirectionalLayout}, []string{"1A", "31A"}},
+	}
+	for _, tc := range cases {
+		c, err := parseChain(tc.layouts)
+		if err != nil {
+			t.Errorf("parseChain(%q) failed: %v", tc.layouts, err)
+			continue
+		}
+		presses, err := FindPresses(tc.codes, tc.layouts)
+		if err != nil {
+			t.Errorf("FindPresses(%q, %q) failed: %v", tc.codes, tc.layouts, err)
+			continue
+		}
+		for i, code := range tc.codes {
+			if expected := bruteForceLength(c, code); len(presses[i]) != expected {
+				t.Errorf("FindPresses(%q, %q) == %q, expected %d presses", code, tc.layouts, presses[i], expected)
+			}
+		}
+	}
+	result, err := CalcChainComplexity([]string{"2A"}, [][]string{winding, DirectionalLayout})
+	if err != nil || result != 2*len("v>>^Av<<^A") {
+		t.Errorf("CalcChainComplexity(2A, winding) == %d, %v, expected %d", result, err, 2*len("v>>^Av<<^A"))
+	}
+}
+
+func TestChainErrors(t *testing.T) {
+	cases := []struct {
+		layouts [][]string
+		codes   []string
+		message string
+	}{
+		{[][]string{{"A 2"}, DirectionalLayout}, []string{"2A"}, "cannot move"},
+		{[][]string{NumericLayout, DirectionalLayout}, []string{"02BA"}, "missing from keypad 0"},
+	}
+	for _, c := range cases {
+		_, err := CalcChainComplexity(c.codes, c.layouts)
+		if err == nil || !strings.Contains(err.Error(), c.message) {
+			t.Errorf("CalcChainComplexity(%q, %q) error == %v, expected %q", c.codes, c.layouts, err, c.message)
+		}
+		_, err = FindPresses(c.codes, c.layouts)
+		if err == nil || !strings.Contains(err.Error(), c.message) {
+			t.Errorf("FindPresses(%q, %q) error == %v, expected %q", c.codes, c.layouts, err, c.message)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package day22
+
+import (
+	"aoc2024/cycle"
+	"log"
+	"math/bits"
+)
+
+// bitMatrix is a square matrix over GF(2) stored by column, so that column j
+// is the image of the state with only bit j set.
+type bitMatrix []uint64
+
+func (m bitMatrix) apply(v uint64) uint64 {
+	result := uint64(0)
+	for v != 0 {
+		j := bits.TrailingZeros64(v)
+		result ^= m[j]
+		v &= v - 1
+	}
+	return result
+}
+
+// mul returns the matrix applying n first and then m.
+func (m bitMatrix) mul(n bitMatrix) bitMatrix {
+	result := make(bitMatrix, len(n))
+	for j, column := range n {
+		result[j] = m.apply(column)
+	}
+	return result
+}
+
+// Generator advances a state of width bits with a step that is linear over
+// GF(2), such as the xorshift steps of the secret number generator.
+type Generator struct {
+	width int
+	mask  uint64
+	// squares[k] is the step matrix raised to the power 2^k.
+	squares []bitMatrix
+}
+
+func NewGenerator(width int, next func(int) int) Generator {
+	if width < 1 || width > 62 {
+		log.Panicf("Generator width %d is outside 1..62", width)
+	}
+	step := make(bitMatrix, width)
+	for j := range width {
+		step[j] = uint64(next(1<<j)) & (1<<width - 1)
+	}
+	squares := []bitMatrix{step}
+	for range bits.UintSize - 2 {
+		last := squares[len(squares)-1]
+		squares = append(squares, last.mul(last))
+	}
+	return Generator{width, 1<<width - 1, squares}
+}
+
+// Nth returns the state n steps after seed in O(width^2 log n).
+func (g Generator) Nth(seed, n int) int {
+	if n < 0 {
+		log.Panicf("Cannot step the generator %d times", n)
+	}
+	state := uint64(seed) & g.mask
+	for k := 0; n != 0; k++ {
+		if n&1 == 1 {
+			state = g.squares[k].apply(state)
+		}
+		n >>= 1
+	}
+	return int(state)
+}
+
+// Period finds the cycle the sequence starting at seed falls into. When the
+// seed returns to itself after 2^width-1 steps, as for a maximal length
+// generator, the period is found by dividing out prime factors of that
+// length; otherwise it falls back to iterating the generator.
+func (g Generator) Period(seed int) cycle.Cycle {
+	seed &= int(g.mask)
+	order := int(g.mask)
+	if g.Nth(seed, order) != seed {
+		return cycle.Brent(seed, func(s int) int { return g.Nth(s, 1) })
+	}
+	for _, p := range primeFactors(order) {
+		for order%p == 0 && g.Nth(seed, order/p) == seed {
+			order /= p
+		}
+	}
+	return cycle.Cycle{Start: 0, Length: order}
+}
+
+func primeFactors(n int) []int {
+	factors := []int{}
+	for p := 2; p*p <= n; p++ {
+		if n%p == 0 {
+			factors = append(factors, p)
+			for n%p == 0 {
+				n /= p
+			}
+		}
+	}
+	if n > 1 {
+		factors = append(factors, n)
+	}
+	return factors
+}
+
+const secretWidth = 24
+
+var secretGenerator = NewGenerator(secretWidth, calcNextSecret)
+
+func SumSecretsAfter(inputs []string, nSecrets int) int {
+	sum := 0
+	for _, seed := range parseSeeds(inputs) {
+		sum += secretGenerator.Nth(seed, nSecrets)
+	}
+	return sum
+}
+
+func SecretPeriods(inputs []string) []int {
+	periods := []int{}
+	for _, seed := range parseSeeds(inputs) {
+		periods = append(periods, secretGenerator.Period(seed).Length)
+	}
+	return periods
+}
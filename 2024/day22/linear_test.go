@@ -0,0 +1,96 @@
+package day22
+
+import (
+	"aoc2024/cycle"
+	"math/rand"
+	"testing"
+)
+
+func TestGeneratorNth(t *testing.T) {
+	r := rand.New(rand.NewSource(22))
+	for range 50 {
+		seed, n := r.Intn(1<<secretWidth), r.Intn(3000)
+		result := secretGenerator.Nth(seed, n)
+		expected := calcFinalSecret(seed, n)
+		if result != expected {
+			t.Errorf("secretGenerator.Nth(%d, %d) == %d, expected %d", seed, n, result, expected)
+		}
+	}
+}
+
+func TestGeneratorNthLarge(t *testing.T) {
+	const n = 1_000_000
+	for _, seed := range []int{1, 10, 100, 2024} {
+		result := secretGenerator.Nth(seed, n)
+		expected := calcFinalSecret(seed, n)
+		if result != expected {
+			t.Errorf("secretGenerator.Nth(%d, %d) == %d, expected %d", seed, n, result, expected)
+		}
+	}
+}
+
+func TestSumSecretsAfter(t *testing.T) {
+	inputs := []string{"1", "10", "100", "2024"}
+	if result := SumSecretsAfter(inputs, 2000); result != 37327623 {
+		t.Errorf("SumSecretsAfter(%q, 2000) == %d, expected %d", inputs, result, 37327623)
+	}
+}
+
+func TestGeneratorPeriod(t *testing.T) {
+	cases := []struct {
+		width int
+		next  func(int) int
+		seed  int
+	}{
+		{8, func(s int) int { s ^= s << 3; s ^= (s & 0xff) >> 5; return s ^ s<<1 }, 1},
+		{8, func(s int) int { s ^= s << 3; s ^= (s & 0xff) >> 5; return s ^ s<<1 }, 77},
+		{10, func(s int) int { return s ^ s<<1 }, 3},
+		{10, func(s int) int { return s<<2 ^ s>>1 }, 5},
+		{6, func(s int) int { return s >> 1 }, 63},
+		{6, func(s int) int { return s }, 0},
+	}
+	for _, c := range cases {
+		g := NewGenerator(c.width, c.next)
+		mask := 1<<c.width - 1
+		expected := bruteForcePeriod(c.seed, func(s int) int { return c.next(s) & mask })
+		if result := g.Period(c.seed); result != expected {
+			t.Errorf("Period(%d) for width %d == %v, expected %v", c.seed, c.width, result, expected)
+		}
+	}
+}
+
+func bruteForcePeriod(seed int, next func(int) int) cycle.Cycle {
+	seen := map[int]int{}
+	for i := 0; ; i++ {
+		if j, ok := seen[seed]; ok {
+			return cycle.Cycle{Start: j, Length: i - j}
+		}
+		seen[seed] = i
+		seed = next(seed)
+	}
+}
+
+func TestSecretPeriods(t *testing.T) {
+	periods := SecretPeriods([]string{"0", "1", "2024"})
+	expected := []int{1, 1<<secretWidth - 1, 1<<secretWidth - 1}
+	for i := range expected {
+		if periods[i] != expected[i] {
+			t.Errorf("SecretPeriods(...)[%d] == %d, expected %d", i, periods[i], expected[i])
+		}
+	}
+	if seed := secretGenerator.Nth(2024, periods[2]); seed != 2024 {
+		t.Errorf("secretGenerator.Nth(2024, %d) == %d, expected 2024", periods[2], seed)
+	}
+}
+
+func BenchmarkGeneratorNth(b *testing.B) {
+	for range b.N {
+		secretGenerator.Nth(2024, 2000)
+	}
+}
+
+func BenchmarkCalcFinalSecret(b *testing.B) {
+	for range b.N {
+		calcFinalSecret(2024, 2000)
+	}
+}
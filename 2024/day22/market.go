@@ -0,0 +1,118 @@
+package day22
+
+import (
+	"log"
+	"runtime"
+	"strconv"
+	"sync"
+	"sync/atomic"
+)
+
+const (
+	nChanges  = 19
+	maxWindow = 5
+)
+
+// packWindow maps a window of price changes, each from -9 to 9, to a
+// dense index below nChanges to the power of the window length.
+func packWindow(changes []int) int {
+	index := 0
+	for _, c := range changes {
+		index = index*nChanges + c + 9
+	}
+	return index
+}
+
+func unpackWindow(index, window int) []int {
+	changes := make([]int, window)
+	for i := window - 1; i >= 0; i-- {
+		changes[i] = index%nChanges - 9
+		index /= nChanges
+	}
+	return changes
+}
+
+// addSellPrices adds to totals the price each window of changes would
+// sell at for one buyer. Only the first occurrence of a window counts,
+// which seen tracks by storing the buyer's stamp. Workers share totals, so
+// the additions are atomic.
+func addSellPrices(totals []int32, seen []uint16, stamp uint16, prices []int, window int) {
+	changes := generatePriceChanges(prices)
+	for i := 0; i+window <= len(changes); i++ {
+		index := packWindow(changes[i : i+window])
+		if seen[index] != stamp {
+			seen[index] = stamp
+			atomic.AddInt32(&totals[index], int32(prices[i+window]))
+		}
+	}
+}
+
+// sellPriceTotals sums, over all buyers, the price each window of changes
+// sells at. Buyers are split between workers which add into one shared
+// array of totals. Each worker only keeps a small stamp per window to skip
+// repeats, clearing the stamps whenever they wrap around.
+func sellPriceTotals(seeds []int, nSecrets int, window int) []int32 {
+	size := 1
+	for range window {
+		size *= nChanges
+	}
+	totals := make([]int32, size)
+	nWorkers := max(1, min(runtime.GOMAXPROCS(0), len(seeds)))
+	var wg sync.WaitGroup
+	for w := range nWorkers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			seen := make([]uint16, size)
+			stamp := uint16(0)
+			for i := w; i < len(seeds); i += nWorkers {
+				stamp++
+				if stamp == 0 {
+					clear(seen)
+					stamp = 1
+				}
+				addSellPrices(totals, seen, stamp, generatePrices(seeds[i], nSecrets), window)
+			}
+		}()
+	}
+	wg.Wait()
+	return totals
+}
+
+func parseSeeds(inputs []string) []int {
+	seeds := []int{}
+	for _, input := range inputs {
+		if input == "" {
+			continue
+		}
+		if seed, err := strconv.Atoi(input); err == nil {
+			seeds = append(seeds, seed)
+		} else {
+			log.Panicf("Cound not parse seed from input %q", input)
+		}
+	}
+	return seeds
+}
+
+// FindBestSequence returns the window of price changes that earns the
+// most bananas across all buyers, and how many it earns. Ties go to the
+// lowest changes in lexical order.
+func FindBestSequence(inputs []string, window int) ([]int, int) {
+	const nSecrets = 2000
+	if window < 1 || window > maxWindow {
+		log.Panicf("Window length %d is not between 1 and %d", window, maxWindow)
+	}
+	totals := sellPriceTotals(parseSeeds(inputs), nSecrets, window)
+	best := 0
+	for i, total := range totals {
+		if total > totals[best] {
+			best = i
+		}
+	}
+	return unpackWindow(best, window), int(totals[best])
+}
+
+func SumSellPrices(inputs []string) int {
+	_, total := FindBestSequence(inputs, 4)
+	return total
+}
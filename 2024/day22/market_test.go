@@ -0,0 +1,92 @@
+package day22
+
+import (
+	"fmt"
+	"runtime"
+	"slices"
+	"testing"
+)
+
+func TestFindBestSequence(t *testing.T) {
+	sequence, total := FindBestSequence([]string{"1", "2", "3", "2024"}, 4)
+	if !slices.Equal(sequence, []int{-2, 1, -1, 3}) || total != 23 {
+		t.Errorf("FindBestSequence(example, 4) == %v, %d, expected [-2 1 -1 3], 23", sequence, total)
+	}
+}
+
+// bestSequenceByMap finds the best window by keying a map on every window
+// each buyer sees.
+func bestSequenceByMap(seeds []int, nSecrets, window int) int {
+	totals := map[string]int{}
+	for _, seed := range seeds {
+		prices := generatePrices(seed, nSecrets)
+		changes := generatePriceChanges(prices)
+		seen := map[string]bool{}
+		for i := 0; i+window <= len(changes); i++ {
+			key := fmt.Sprint(changes[i : i+window])
+			if !seen[key] {
+				seen[key] = true
+				totals[key] += prices[i+window]
+			}
+		}
+	}
+	best := 0
+	for _, total := range totals {
+		best = max(best, total)
+	}
+	return best
+}
+
+func TestSellPriceTotalsMatchMap(t *testing.T) {
+	seeds := []int{1, 2, 3, 2024, 123, 100, 10}
+	for window := 1; window <= maxWindow; window++ {
+		totals := sellPriceTotals(seeds, 500, window)
+		expected := bestSequenceByMap(seeds, 500, window)
+		if result := int(slices.Max(totals)); result != expected {
+			t.Errorf("best total for window %d == %d, expected %d", window, result, expected)
+		}
+	}
+}
+
+func TestSellPriceTotalsStampWrap(t *testing.T) {
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(1))
+	// The first buyer after the stamps wrap reuses the first buyer's stamp,
+	// so give them the same seed and everyone in between another one.
+	seeds := slices.Repeat([]int{2024}, 70000)
+	seeds[0], seeds[1<<16-1] = 1, 1
+	expected := make([]int32, nChanges*nChanges)
+	for _, seed := range seeds {
+		prices := generatePrices(seed, 6)
+		changes := generatePriceChanges(prices)
+		seen := map[int]bool{}
+		for i := 0; i+2 <= len(changes); i++ {
+			index := packWindow(changes[i : i+2])
+			if !seen[index] {
+				seen[index] = true
+				expected[index] += int32(prices[i+2])
+			}
+		}
+	}
+	if totals := sellPriceTotals(seeds, 6, 2); !slices.Equal(totals, expected) {
+		t.Errorf("sellPriceTotals over %d buyers does not match the per buyer totals", len(seeds))
+	}
+}
+
+func TestPackWindow(t *testing.T) {
+	for _, changes := range [][]int{{-9}, {9}, {0, 0}, {-2, 1, -1, 3}, {9, -9, 9, -9, 9}} {
+		index := packWindow(changes)
+		if result := unpackWindow(index, len(changes)); !slices.Equal(result, changes) {
+			t.Errorf("unpackWindow(packWindow(%v)) == %v", changes, result)
+		}
+	}
+}
+
+func BenchmarkSellPriceTotals(b *testing.B) {
+	seeds := make([]int, 2000)
+	for i := range seeds {
+		seeds[i] = 7919 * (i + 1)
+	}
+	for range b.N {
+		sellPriceTotals(seeds, 2000, 4)
+	}
+}
@@ -1,9 +1,7 @@
 package day23
 
 import (
-	"fmt"
 	"iter"
-	"log"
 	"slices"
 	"strings"
 )
@@ -124,16 +122,6 @@ func (g *graph[T]) getNeighborSet(id int) set[int] {
 	return g.neighborSets[id]
 }
 
-func (g *graph[T]) allNodes() iter.Seq2[int, T] {
-	return func(yield func(int, T) bool) {
-		for i, n := range g.nodes {
-			if !yield(i, n) {
-				break
-			}
-		}
-	}
-}
-
 func (g *graph[T]) expandClique(ids set[int]) iter.Seq[set[int]] {
 	return func(yield func(set[int]) bool) {
 		neighborSets := []set[int]{}
@@ -175,25 +163,161 @@ func (g *graph[T]) allCliques() iter.Seq[set[int]] {
 	}
 }
 
-func (g *graph[T]) getInducedSubgraph(ids set[int]) *graph[T] {
-	induced := newGraph[T]()
-	inducedToParent := map[int]int{}
-	parentToInduced := map[int]int{}
-	for id := range ids.all() {
-		inducedId := induced.addNode(g.nodes[id])
-		inducedToParent[inducedId] = id
-		parentToInduced[id] = inducedId
+// degeneracyOrder repeatedly removes a node of minimum remaining degree, so
+// that every node has few neighbors later in the order.
+func (g *graph[T]) degeneracyOrder() []int {
+	n := len(g.nodes)
+	degrees := make([]int, n)
+	buckets := [][]int{}
+	for id := range g.nodes {
+		degrees[id] = g.neighborSets[id].size()
+		for len(buckets) <= degrees[id] {
+			buckets = append(buckets, []int{})
+		}
+		buckets[degrees[id]] = append(buckets[degrees[id]], id)
+	}
+	removed := make([]bool, n)
+	order := make([]int, 0, n)
+	d := 0
+	for len(order) < n {
+		if len(buckets[d]) == 0 {
+			d++
+			continue
+		}
+		id := buckets[d][len(buckets[d])-1]
+		buckets[d] = buckets[d][:len(buckets[d])-1]
+		if removed[id] || degrees[id] != d {
+			continue
+		}
+		removed[id] = true
+		order = append(order, id)
+		for nid := range g.neighborSets[id].all() {
+			if !removed[nid] {
+				degrees[nid]--
+				buckets[degrees[nid]] = append(buckets[degrees[nid]], nid)
+			}
+		}
+		d = max(d-1, 0)
+	}
+	return order
+}
+
+func (g *graph[T]) bronKerbosch(r, p, x set[int], yield func(set[int]) bool) bool {
+	if p.size() == 0 {
+		if x.size() == 0 {
+			return yield(r)
+		}
+		return true
 	}
-	for id := range induced.allNodes() {
-		parentId := inducedToParent[id]
-		neighbors := g.getNeighborSet(parentId)
-		for n := range neighbors.all() {
-			if id != parentToInduced[n] && ids.contains(n) {
-				induced.addEdge(id, connection{parentToInduced[n], 1})
+	pivot, maxShared := -1, -1
+	for _, s := range []set[int]{p, x} {
+		for u := range s.all() {
+			shared := 0
+			neighbors := g.getNeighborSet(u)
+			for v := range p.all() {
+				if neighbors.contains(v) {
+					shared++
+				}
+			}
+			if shared > maxShared {
+				pivot, maxShared = u, shared
+			}
+		}
+	}
+	pivotNeighbors := g.getNeighborSet(pivot)
+	candidates := []int{}
+	for v := range p.all() {
+		if !pivotNeighbors.contains(v) {
+			candidates = append(candidates, v)
+		}
+	}
+	for _, v := range candidates {
+		neighbors := g.getNeighborSet(v)
+		newR := r.clone()
+		newR.add(v)
+		if !g.bronKerbosch(newR, intersection(p, neighbors), intersection(x, neighbors), yield) {
+			return false
+		}
+		p.remove(v)
+		x.add(v)
+	}
+	return true
+}
+
+// maximalCliques enumerates every maximal clique exactly once using
+// Bron-Kerbosch with pivoting, started from each node in degeneracy order.
+func (g *graph[T]) maximalCliques() iter.Seq[set[int]] {
+	return func(yield func(set[int]) bool) {
+		order := g.degeneracyOrder()
+		position := make([]int, len(order))
+		for i, id := range order {
+			position[id] = i
+		}
+		for _, id := range order {
+			r, p, x := newSet[int](), newSet[int](), newSet[int]()
+			r.add(id)
+			neighbors := g.getNeighborSet(id)
+			for nid := range neighbors.all() {
+				if position[nid] > position[id] {
+					p.add(nid)
+				} else {
+					x.add(nid)
+				}
+			}
+			if !g.bronKerbosch(r, p, x, yield) {
+				return
+			}
+		}
+	}
+}
+
+// cliquesOfSize enumerates every clique of k nodes exactly once, extending
+// each node only with neighbors later in degeneracy order.
+func (g *graph[T]) cliquesOfSize(k int) iter.Seq[[]int] {
+	return func(yield func([]int) bool) {
+		order := g.degeneracyOrder()
+		position := make([]int, len(order))
+		for i, id := range order {
+			position[id] = i
+		}
+		var extend func(clique, candidates []int) bool
+		extend = func(clique, candidates []int) bool {
+			if len(clique) == k {
+				return yield(slices.Clone(clique))
+			}
+			for i, id := range candidates {
+				neighbors := g.getNeighborSet(id)
+				next := []int{}
+				for _, cid := range candidates[i+1:] {
+					if neighbors.contains(cid) {
+						next = append(next, cid)
+					}
+				}
+				if len(clique)+1+len(next) < k {
+					continue
+				}
+				if !extend(append(clique, id), next) {
+					return false
+				}
+			}
+			return true
+		}
+		for _, id := range order {
+			later := []int{}
+			neighbors := g.getNeighborSet(id)
+			for nid := range neighbors.all() {
+				if position[nid] > position[id] {
+					later = append(later, nid)
+				}
+			}
+			slices.SortFunc(later, func(a, b int) int {
+				return position[a] - position[b]
+			})
+			if !extend([]int{id}, later) {
+				return
 			}
 		}
 	}
-	return induced
 }
 
 func parseGraph(inputs []string) *graph[string] {
@@ -218,23 +342,23 @@ func parseGraph(inputs []string) *graph[string] {
 }
 
 func CountLANs(inputs []string) int {
+	return CountLANsOfSize(inputs, 3)
+}
+
+// CountLANsOfSize counts the sets of k fully connected computers in which at
+// least one computer name starts with a t.
+func CountLANsOfSize(inputs []string, k int) int {
 	g := parseGraph(inputs)
-	cliques := newSet[[3]int]()
-	for id, node := range g.allNodes() {
-		if node[0] == 't' {
-			neighbors := g.getNeighborSet(id)
-			for nid := range neighbors.all() {
-				nextNeighbors := g.getNeighborSet(nid)
-				shared := intersection(neighbors, nextNeighbors)
-				for nnid := range shared.all() {
-					lan := []int{id, nid, nnid}
-					slices.Sort(lan)
-					cliques.add([3]int(lan))
-				}
+	count := 0
+	for clique := range g.cliquesOfSize(k) {
+		for _, id := range clique {
+			if g.getNode(id)[0] == 't' {
+				count++
+				break
 			}
 		}
 	}
-	return cliques.size()
+	return count
 }
 
 func getCliquePassword(g *graph[string], clique set[int]) string {
@@ -250,29 +374,7 @@ func FindPassword(inputs []string) string {
 	var maxClique set[int]
 	maxCliqueSize := 0
 	g := parseGraph(inputs)
-	inducedSubgraphs := map[string]*graph[string]{}
-	for id := range g.allNodes() {
-		ids := g.getNeighborSet(id)
-		ids.add(id)
-		for rid := range ids.all() {
-			reduced := ids.clone()
-			reduced.remove(rid)
-			induced := g.getInducedSubgraph(reduced)
-			inducedIds := newSet[int]()
-			for i := range induced.allNodes() {
-				inducedIds.add(i)
-			}
-			inducedSubgraphs[getCliquePassword(induced, inducedIds)] = induced
-		}
-
-	}
-	log.Printf("Found %d induced subgraphs", len(inducedSubgraphs))
-	for k, v := range inducedSubgraphs {
-		if v.nEdges == len(v.nodes)*(len(v.nodes)-1) {
-			fmt.Printf("Subgraph %q has %d edges\n", k, v.nEdges)
-		}
-	}
-	for clique := range g.allCliques() {
+	for clique := range g.maximalCliques() {
 		cliqueSize := clique.size()
 		if cliqueSize > maxCliqueSize {
 			maxClique = clique
@@ -1,6 +1,47 @@
 package day23
 
-import "testing"
+import (
+	"fmt"
+	"math/rand"
+	"slices"
+	"strings"
+	"testing"
+)
+
+var example = []string{
+	"kh-tc",
+	"qp-kh",
+	"de-cg",
+	"ka-co",
+	"yn-aq",
+	"qp-ub",
+	"cg-tb",
+	"vc-aq",
+	"tb-ka",
+	"wh-tc",
+	"yn-cg",
+	"kh-ub",
+	"ta-co",
+	"de-co",
+	"tc-td",
+	"tb-wq",
+	"wh-td",
+	"ta-ka",
+	"td-qp",
+	"aq-cg",
+	"wq-ub",
+	"ub-vc",
+	"de-ta",
+	"wq-aq",
+	"wq-vc",
+	"wh-yn",
+	"ka-de",
+	"kh-ta",
+	"co-tc",
+	"wh-qp",
+	"tb-vc",
+	"td-yn",
+}
 
 func TestCountLANs(t *testing.T) {
 	cases := []struct {
@@ -55,7 +96,6 @@ func TestCountLANs(t *testing.T) {
 	}
 }
 
-/*
 func TestFindPassword(t *testing.T) {
 	cases := []struct {
 		inputs   []string
@@ -108,4 +148,81 @@ func TestFindPassword(t *testing.T) {
 		}
 	}
 }
-*/
+
+func TestCountLANsOfSize(t *testing.T) {
+	cases := []struct {
+		inputs   []string
+		k        int
+		expected int
+	}{
+		{example, 2, 15},
+		{example, 3, 7},
+		{example, 4, 1},
+		{example, 5, 0},
+		{[]string{"ta-b", "ta-c", "ta-d", "b-c", "b-d", "c-d"}, 4, 1},
+		{[]string{"a-b", "a-c", "a-d", "b-c", "b-d", "c-d"}, 3, 0},
+	}
+	for _, c := range cases {
+		result := CountLANsOfSize(c.inputs, c.k)
+		if result != c.expected {
+			t.Errorf("CountLANsOfSize(%q, %d) == %d, expected %d",
+				c.inputs, c.k, result, c.expected,
+			)
+		}
+	}
+}
+
+func cliqueNames(g *graph[string], cliques func(func(set[int]) bool)) []string {
+	names := []string{}
+	for clique := range cliques {
+		names = append(names, getCliquePassword(g, clique))
+	}
+	slices.Sort(names)
+	return slices.Compact(names)
+}
+
+func randomGraphInputs(n int, p float64, seed int64) []string {
+	r := rand.New(rand.NewSource(seed))
+	inputs := []string{}
+	for i := range n {
+		for j := i + 1; j < n; j++ {
+			if r.Float64() < p {
+				inputs = append(inputs, fmt.Sprintf("n%d-n%d", i, j))
+			}
+		}
+	}
+	return inputs
+}
+
+func TestMaximalCliques(t *testing.T) {
+	for _, inputs := range [][]string{example, randomGraphInputs(30, 0.4, 1)} {
+		g := parseGraph(inputs)
+		result := []string{}
+		for clique := range g.maximalCliques() {
+			result = append(result, getCliquePassword(g, clique))
+		}
+		slices.Sort(result)
+		expected := cliqueNames(g, g.allCliques())
+		if !slices.Equal(result, expected) {
+			t.Errorf("maximalCliques() == %v, expected %v",
+				strings.Join(result, " "), strings.Join(expected, " "),
+			)
+		}
+	}
+}
+
+func BenchmarkAllCliques(b *testing.B) {
+	g := parseGraph(randomGraphInputs(40, 0.4, 1))
+	for range b.N {
+		for range g.allCliques() {
+		}
+	}
+}
+
+func BenchmarkMaximalCliques(b *testing.B) {
+	g := parseGraph(randomGraphInputs(40, 0.4, 1))
+	for range b.N {
+		for range g.maximalCliques() {
+		}
+	}
+}
@@ -0,0 +1,223 @@
+package day24
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+)
+
+const (
+	bddFalse = 0
+	bddTrue  = 1
+)
+
+type bddNode struct {
+	variable, low, high int
+}
+
+// bdd is a reduced ordered binary decision diagram manager. Every function
+// has a single node, so two functions are equal exactly when their node
+// indices are.
+type bdd struct {
+	nodes  []bddNode
+	unique map[bddNode]int
+	cache  map[[3]int]int
+}
+
+func newBDD() *bdd {
+	terminal := math.MaxInt
+	return &bdd{
+		nodes:  []bddNode{{terminal, bddFalse, bddFalse}, {terminal, bddTrue, bddTrue}},
+		unique: map[bddNode]int{},
+		cache:  map[[3]int]int{},
+	}
+}
+
+func (b *bdd) node(variable, low, high int) int {
+	if low == high {
+		return low
+	}
+	n := bddNode{variable, low, high}
+	if id, ok := b.unique[n]; ok {
+		return id
+	}
+	id := len(b.nodes)
+	b.nodes = append(b.nodes, n)
+	b.unique[n] = id
+	return id
+}
+
+func (b *bdd) variable(v int) int {
+	return b.node(v, bddFalse, bddTrue)
+}
+
+func (b *bdd) cofactors(f, v int) (int, int) {
+	if n := b.nodes[f]; n.variable == v {
+		return n.low, n.high
+	}
+	return f, f
+}
+
+// ite computes if f then g else h, from which every other operation is
+// built.
+func (b *bdd) ite(f, g, h int) int {
+	switch {
+	case f == bddTrue:
+		return g
+	case f == bddFalse:
+		return h
+	case g == h:
+		return g
+	case g == bddTrue && h == bddFalse:
+		return f
+	}
+	key := [3]int{f, g, h}
+	if id, ok := b.cache[key]; ok {
+		return id
+	}
+	v := min(b.nodes[f].variable, b.nodes[g].variable, b.nodes[h].variable)
+	f0, f1 := b.cofactors(f, v)
+	g0, g1 := b.cofactors(g, v)
+	h0, h1 := b.cofactors(h, v)
+	id := b.node(v, b.ite(f0, g0, h0), b.ite(f1, g1, h1))
+	b.cache[key] = id
+	return id
+}
+
+func (b *bdd) not(f int) int {
+	return b.ite(f, bddFalse, bddTrue)
+}
+
+func (b *bdd) and(f, g int) int {
+	return b.ite(f, g, bddFalse)
+}
+
+func (b *bdd) or(f, g int) int {
+	return b.ite(f, bddTrue, g)
+}
+
+func (b *bdd) xor(f, g int) int {
+	return b.ite(f, b.not(g), g)
+}
+
+func (b *bdd) apply(operation string, f, g int) int {
+	switch operation {
+	case "AND":
+		return b.and(f, g)
+	case "OR":
+		return b.or(f, g)
+	case "XOR":
+		return b.xor(f, g)
+	case "NAND":
+		return b.not(b.and(f, g))
+	case "NOR":
+		return b.not(b.or(f, g))
+	case "XNOR":
+		return b.not(b.xor(f, g))
+	case "NOT":
+		return b.not(f)
+	}
+	panic(fmt.Sprintf("unknown gate operation %q", operation))
+}
+
+// satisfy returns an assignment making f true, leaving variables that do
+// not matter on the chosen path false.
+func (b *bdd) satisfy(f int) (map[int]bool, bool) {
+	if f == bddFalse {
+		return nil, false
+	}
+	assignment := map[int]bool{}
+	for f != bddTrue {
+		n := b.nodes[f]
+		if n.low != bddFalse {
+			assignment[n.variable] = false
+			f = n.low
+		} else {
+			assignment[n.variable] = true
+			f = n.high
+		}
+	}
+	return assignment, true
+}
+
+type Counterexample struct {
+	Output string
+	X, Y   int
+}
+
+func (c Counterexample) String() string {
+	return fmt.Sprintf("%s is wrong for x=%d, y=%d", c.Output, c.X, c.Y)
+}
+
+// adderVariable orders the inputs x00, y00, x01, y01, ... which keeps the
+// diagrams of a ripple carry adder linear in its width.
+func adderVariable(name string, nBits int) (int, error) {
+	if len(name) == 3 && (name[0] == 'x' || name[0] == 'y') {
+		if i, err := strconv.Atoi(name[1:]); err == nil && i < nBits {
+			if name[0] == 'x' {
+				return 2 * i, nil
+			}
+			return 2*i + 1, nil
+		}
+	}
+	return 0, fmt.Errorf("input wire %s is not an operand bit of a %d bit adder", name, nBits)
+}
+
+// verifyAdder proves that every output zNN of the circuit equals bit NN of
+// x + y for all inputs, returning a counterexample for each output that
+// does not.
+func verifyAdder(gates []gate, nBits int) ([]Counterexample, error) {
+	c, err := compile(gates)
+	if err != nil {
+		return nil, err
+	}
+	b := newBDD()
+	functions := make([]int, len(c.names))
+	for _, id := range c.inputs {
+		v, err := adderVariable(c.names[id], nBits)
+		if err != nil {
+			return nil, err
+		}
+		functions[id] = b.variable(v)
+	}
+	for _, g := range c.gates {
+		inputB := bddFalse
+		if g.inputB >= 0 {
+			inputB = functions[g.inputB]
+		}
+		functions[g.output] = b.apply(g.operation, functions[g.inputA], inputB)
+	}
+
+	counterexamples := []Counterexample{}
+	carry := bddFalse
+	for i := 0; i <= nBits; i++ {
+		expected := carry
+		if i < nBits {
+			x, y := b.variable(2*i), b.variable(2*i+1)
+			expected = b.xor(b.xor(x, y), carry)
+			carry = b.or(b.and(x, y), b.and(carry, b.xor(x, y)))
+		}
+		name := fmt.Sprintf("z%02d", i)
+		actual := bddFalse
+		if id, ok := c.wireIds[name]; ok {
+			actual = functions[id]
+		}
+		if assignment, ok := b.satisfy(b.xor(actual, expected)); ok {
+			counterexample := Counterexample{Output: name}
+			for v, value := range assignment {
+				if value && v%2 == 0 {
+					counterexample.X |= 1 << (v / 2)
+				} else if value {
+					counterexample.Y |= 1 << (v / 2)
+				}
+			}
+			counterexamples = append(counterexamples, counterexample)
+		}
+	}
+	return counterexamples, nil
+}
+
+func VerifyAdder(inputs []string) ([]Counterexample, error) {
+	_, gates := parseInputs(inputs)
+	return verifyAdder(gates, countBits(gates))
+}
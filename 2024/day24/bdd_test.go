@@ -0,0 +1,118 @@
+package day24
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+)
+
+func TestBDD(t *testing.T) {
+	b := newBDD()
+	x, y, z := b.variable(0), b.variable(1), b.variable(2)
+	cases := []struct {
+		name           string
+		result, expect int
+	}{
+		{"x XOR x", b.xor(x, x), bddFalse},
+		{"x AND NOT x", b.and(x, b.not(x)), bddFalse},
+		{"x OR NOT x", b.or(x, b.not(x)), bddTrue},
+		{"NOT NOT x", b.not(b.not(x)), x},
+		{"(x AND y) OR (x AND z)", b.or(b.and(x, y), b.and(x, z)), b.and(x, b.or(y, z))},
+		{"NAND(x, y)", b.apply("NAND", x, y), b.or(b.not(x), b.not(y))},
+		{"XNOR(x, y)", b.apply("XNOR", x, y), b.xor(x, b.not(y))},
+	}
+	for _, c := range cases {
+		if c.result != c.expect {
+			t.Errorf("%s == node %d, expected node %d", c.name, c.result, c.expect)
+		}
+	}
+
+	f := b.and(b.not(x), b.xor(y, z))
+	assignment, ok := b.satisfy(f)
+	if !ok || assignment[0] || assignment[1] == assignment[2] {
+		t.Errorf("satisfy(NOT x AND (y XOR z)) == %v, %t", assignment, ok)
+	}
+	if _, ok := b.satisfy(bddFalse); ok {
+		t.Errorf("satisfy(false) found an assignment")
+	}
+}
+
+// checkCounterexamples confirms by simulation that each counterexample
+// really produces a wrong output bit.
+func checkCounterexamples(t *testing.T, inputs []string, nBits int, counterexamples []Counterexample) {
+	t.Helper()
+	_, gates := parseInputs(inputs)
+	c, err := compile(gates)
+	if err != nil {
+		t.Fatalf("compile() returned error %v", err)
+	}
+	for _, ce := range counterexamples {
+		s := c.newSimulator()
+		s.setOperand("x", nBits, []int{ce.X})
+		s.setOperand("y", nBits, []int{ce.Y})
+		s.run()
+		var bit int
+		fmt.Sscanf(ce.Output, "z%02d", &bit)
+		z := s.getOperand("z", 1)[0]
+		if (z>>bit)&1 == ((ce.X+ce.Y)>>bit)&1 {
+			t.Errorf("counterexample %v gives the correct %s", ce, ce.Output)
+		}
+	}
+}
+
+func TestVerifyAdder(t *testing.T) {
+	faulty := adderInputs(8, 0, 0, nil)
+	i := slices.Index(faulty, "c04 XOR p05 -> z05")
+	faulty[i] = "c04 XOR p05 -> s05"
+	faulty = append(faulty,
+		"x00 AND y01 -> f01",
+		"f01 AND x02 -> f02",
+		"NOT y02 -> f03",
+		"f02 AND f03 -> f04",
+		"s05 XOR f04 -> z05",
+	)
+	xorCarry := adderInputs(8, 0, 0, nil)
+	i = slices.Index(xorCarry, "g03 OR t03 -> c03")
+	xorCarry[i] = "g03 XOR t03 -> c03"
+
+	cases := []struct {
+		name     string
+		inputs   []string
+		nBits    int
+		expected []string
+	}{
+		{"8 bit adder", adderInputs(8, 0, 0, nil), 8, []string{}},
+		{"45 bit adder", adderInputs(45, 0, 0, nil), 45, []string{}},
+		{"1 bit adder", adderInputs(1, 0, 0, nil), 1, []string{}},
+		{"carry OR replaced by XOR", xorCarry, 8, []string{}},
+		{"fault hidden from single bit inputs", faulty, 8, []string{"z05"}},
+		{"swapped carry", adderInputs(8, 0, 0, [][2]string{{"z03", "g05"}}), 8, []string{"z03", "z06", "z07", "z08"}},
+	}
+	for _, c := range cases {
+		counterexamples, err := VerifyAdder(c.inputs)
+		if err != nil {
+			t.Errorf("VerifyAdder(%s) returned error %v", c.name, err)
+			continue
+		}
+		outputs := []string{}
+		for _, ce := range counterexamples {
+			outputs = append(outputs, ce.Output)
+		}
+		if !slices.Equal(outputs, c.expected) {
+			t.Errorf("VerifyAdder(%s) == %v, expected failing outputs %v", c.name, counterexamples, c.expected)
+		}
+		checkCounterexamples(t, c.inputs, c.nBits, counterexamples)
+	}
+}
+
+func TestVerifyAdderErrors(t *testing.T) {
+	cases := [][]string{
+		{"x00: 0", "y00: 0", "", "x00 AND w00 -> z00"},
+		{"x00: 0", "y00: 0", "", "x00 AND z00 -> aaa", "aaa OR y00 -> z00"},
+	}
+	for _, inputs := range cases {
+		if _, err := VerifyAdder(inputs); err == nil {
+			t.Errorf("VerifyAdder(%q) returned no error", inputs)
+		}
+	}
+}
@@ -1,95 +1,18 @@
 package day24
 
 import (
-	"errors"
 	"fmt"
-	"iter"
 	"log"
-	//"maps"
 	"regexp"
-	//"slices"
+	"slices"
+	"strings"
 )
 
 const (
-	initialValuePattern = `^((x|y)[0-9]{2}): (0|1)$`
-	gatePattern         = `^([a-z][0-9a-z]{2}) ((AND)|(OR)|(XOR)) ([a-z][0-9a-z]{2}) -> ([a-z][0-9a-z]{2})$`
+	initialValuePattern = `^([a-z][0-9a-z]{2}): (0|1)$`
+	gatePattern         = `^(?:([a-z][0-9a-z]{2}) (AND|OR|XOR|NAND|NOR|XNOR) ([a-z][0-9a-z]{2})|(NOT) ([a-z][0-9a-z]{2})) -> ([a-z][0-9a-z]{2})$`
 )
 
-type wire struct {
-	value []bool
-}
-
-func newWire() *wire {
-	return &wire{
-		value: make([]bool, 0, 1),
-	}
-}
-
-func (w *wire) send(value bool) {
-	if len(w.value) == 0 {
-		w.value = append(w.value, value)
-	} else {
-		w.value[0] = value
-	}
-}
-
-func (w *wire) recv() (value bool, ok bool) {
-	if len(w.value) == 1 {
-		value = w.value[0]
-		ok = true
-	}
-	return
-}
-
-func (w *wire) hasValue() bool {
-	return len(w.value) == 1
-}
-
-type executor struct {
-	gates []struct {
-		gate                   func(*wire, *wire, *wire)
-		inputA, inputB, output *wire
-	}
-}
-
-func newExecutor() *executor {
-	return new(executor)
-}
-
-func (e *executor) exec(f func(*wire, *wire, *wire), inputA, inputB, output *wire) {
-	e.gates = append(e.gates, struct {
-		gate   func(*wire, *wire, *wire)
-		inputA *wire
-		inputB *wire
-		output *wire
-	}{
-		f,
-		inputA,
-		inputB,
-		output,
-	})
-}
-
-func (e *executor) loop() error {
-	remaining := len(e.gates)
-	for {
-		remainingStart := remaining
-		for _, gate := range e.gates {
-			if !gate.output.hasValue() && gate.inputA.hasValue() && gate.inputB.hasValue() {
-				gate.gate(gate.inputA, gate.inputB, gate.output)
-				remaining--
-			}
-		}
-		if remaining == 0 {
-			break
-		}
-		if remaining == remainingStart {
-			return errors.New("deadlock")
-		}
-	}
-	return nil
-}
-
 type initialValue struct {
 	name  string
 	value bool
@@ -121,393 +44,241 @@ func parseInputs(inputs []string) ([]initialValue, []gate) {
 		match := matcher.FindStringSubmatch(input)
 		if inInitialValues {
 			name := match[1]
-			switch digit := match[3]; digit {
+			switch digit := match[2]; digit {
 			case "0":
 				initialValues = append(initialValues, initialValue{name, false})
 			case "1":
 				initialValues = append(initialValues, initialValue{name, true})
 			}
 		} else {
-			inputA := match[1]
-			operation := match[2]
-			inputB := match[6]
-			output := match[7]
-			gates = append(gates, gate{operation, inputA, inputB, output})
+			if match[4] == "NOT" {
+				gates = append(gates, gate{match[4], match[5], "", match[6]})
+			} else {
+				gates = append(gates, gate{match[2], match[1], match[3], match[6]})
+			}
 		}
 	}
 	return initialValues, gates
 }
 
-func and(inputA, inputB, output *wire) {
-	var a, b, ok bool
-	if a, ok = inputA.recv(); !ok {
-		return
-	}
-	inputA.send(a)
-	if b, ok = inputB.recv(); !ok {
-		return
-	}
-	inputB.send(b)
-	output.send(a && b)
-}
-
-func or(inputA, inputB, output *wire) {
-	var a, b, ok bool
-	if a, ok = inputA.recv(); !ok {
-		return
-	}
-	inputA.send(a)
-	if b, ok = inputB.recv(); !ok {
-		return
+func Evaluate(inputs []string) int {
+	initialValues, gates := parseInputs(inputs)
+	c, err := compile(gates)
+	if err != nil {
+		log.Panic(err)
 	}
-	inputB.send(b)
-	output.send(a || b)
-}
-
-func xor(inputA, inputB, output *wire) {
-	var a, b, ok bool
-	if a, ok = inputA.recv(); !ok {
-		return
+	s := c.newSimulator()
+	for _, v := range initialValues {
+		word := uint64(0)
+		if v.value {
+			word = 1
+		}
+		if err := s.set(v.name, word); err != nil {
+			log.Panic(err)
+		}
 	}
-	inputA.send(a)
-	if b, ok = inputB.recv(); !ok {
-		return
+	if err := s.run(); err != nil {
+		log.Panic(err)
 	}
-	inputB.send(b)
-	output.send(a != b)
+	return s.getOperand("z", 1)[0]
 }
 
-func startGates(gates []gate) (map[string]*wire, *executor) {
-	var (
-		inputA, inputB, output *wire
-		ok                     bool
-	)
-	wires := map[string]*wire{}
-	e := newExecutor()
+func countBits(gates []gate) int {
+	nBits := 0
 	for _, gate := range gates {
-		if inputA, ok = wires[gate.inputA]; !ok {
-			inputA = newWire()
-			wires[gate.inputA] = inputA
-		}
-		if inputB, ok = wires[gate.inputB]; !ok {
-			inputB = newWire()
-			wires[gate.inputB] = inputB
-		}
-		if output, ok = wires[gate.output]; !ok {
-			output = newWire()
-			wires[gate.output] = output
-		}
-		switch gate.operation {
-		case "AND":
-			e.exec(and, inputA, inputB, output)
-		case "OR":
-			e.exec(or, inputA, inputB, output)
-		case "XOR":
-			e.exec(xor, inputA, inputB, output)
+		for _, name := range []string{gate.inputA, gate.inputB} {
+			var i int
+			if _, err := fmt.Sscanf(name, "x%02d", &i); err == nil && i >= nBits {
+				nBits = i + 1
+			}
 		}
 	}
-	return wires, e
+	return nBits
 }
 
-func computeResult(wires map[string]*wire, initialValues []initialValue, e *executor) (int, error) {
-	for _, initialValue := range initialValues {
-		wires[initialValue.name].send(initialValue.value)
-	}
-	err := e.loop()
-	if err != nil {
-		return 0, err
-	}
-	result := 0
-	bitCount := 0
-	for {
-		name := fmt.Sprintf("z%02d", bitCount)
-		wire, ok := wires[name]
-		if !ok {
-			break
-		}
-		if value, _ := wire.recv(); value {
-			result ^= 1 << bitCount
-		}
-		bitCount++
-	}
-	return result, nil
+func isOutputWire(name string) bool {
+	return name[0] == 'z'
 }
 
-func Evaluate(inputs []string) int {
-	initialValues, gates := parseInputs(inputs)
-	wires, e := startGates(gates)
-	result, _ := computeResult(wires, initialValues, e)
-	return result
+// rewiring is a netlist with some gate outputs swapped while a repairer
+// walks it bit by bit. Gates keep their positions, so steps refer to them by
+// index across swaps.
+type rewiring struct {
+	gates   []gate
+	swapped []string
 }
 
-func generateInitalValues(x, y, nBits int) []initialValue {
-	initialValues := []initialValue{}
-	for i := range nBits {
-		initialValues = append(initialValues,
-			initialValue{
-				name:  fmt.Sprintf("x%02d", i),
-				value: x&(1<<i) != 0,
-			},
-			initialValue{
-				name:  fmt.Sprintf("y%02d", i),
-				value: y&(1<<i) != 0,
-			},
-		)
-	}
-	return initialValues
+type step struct {
+	r    rewiring
+	gate int
 }
 
-type node struct {
-	id             string
-	childA, childB *node
-}
-
-type tree struct {
-	nodes      map[string]*node
-	edgeValues map[[2]string]string
+// readers lists the gates applying operation to wire name, with the other
+// input each one reads.
+func (r rewiring) readers(operation, name string) ([]int, []string) {
+	ids, others := []int{}, []string{}
+	for i, g := range r.gates {
+		if g.operation != operation {
+			continue
+		}
+		switch name {
+		case g.inputA:
+			ids, others = append(ids, i), append(others, g.inputB)
+		case g.inputB:
+			ids, others = append(ids, i), append(others, g.inputA)
+		}
+	}
+	return ids, others
 }
 
-func newTree() *tree {
-	return &tree{
-		nodes:      map[string]*node{},
-		edgeValues: map[[2]string]string{},
+// gateOf returns the gate applying operation to wires a and b, or -1.
+func (r rewiring) gateOf(operation, a, b string) int {
+	ids, others := r.readers(operation, a)
+	if k := slices.Index(others, b); k >= 0 {
+		return ids[k]
 	}
+	return -1
 }
 
-func (t *tree) addNode(id string) {
-	t.nodes[id] = &node{id: id}
+// repairer searches for at most maxSwaps swaps that turn a circuit into a
+// ripple carry adder, noting whether the limit cut the search short.
+type repairer struct {
+	nBits, maxSwaps int
+	limited         bool
 }
 
-func (t *tree) addEdge(parentId, childId, value string) bool {
-	if parent, ok := t.nodes[parentId]; ok {
-		if child, ok := t.nodes[childId]; ok {
-			if parent.childA == nil {
-				parent.childA = child
-				t.edgeValues[[2]string{parentId, childId}] = value
-				return true
-			}
-			if parent.childB == nil {
-				parent.childB = child
-				t.edgeValues[[2]string{parentId, childId}] = value
-				return true
-			}
-		}
-	}
-	return false
+// swap names the output of gate i name, giving the gate that drove name the
+// old output instead. A wire is never swapped twice.
+func (p *repairer) swap(r rewiring, i int, name string) (rewiring, bool) {
+	j := slices.IndexFunc(r.gates, func(g gate) bool { return g.output == name })
+	output := r.gates[i].output
+	if j < 0 || j == i || slices.Contains(r.swapped, name) || slices.Contains(r.swapped, output) {
+		return r, false
+	}
+	if len(r.swapped) == 2*p.maxSwaps {
+		p.limited = true
+		return r, false
+	}
+	gates := slices.Clone(r.gates)
+	gates[i].output, gates[j].output = name, output
+	return rewiring{gates, append(slices.Clone(r.swapped), name, output)}, true
 }
 
-func (t *tree) connectedNodes(rootId string) iter.Seq2[string, *node] {
-	return func(yield func(string, *node) bool) {
-		nodeIds := []string{rootId}
-		size := 1
-		for size > 0 {
-			size--
-			nodeId := nodeIds[size]
-			nodeIds = nodeIds[:size]
-			node := t.nodes[nodeId]
-			if yield(nodeId, node) {
-				if node.childA != nil && node.childB != nil {
-					nodeIds = append(nodeIds, node.childA.id, node.childB.id)
-					size += 2
-				} else if node.childA != nil {
-					nodeIds = append(nodeIds, node.childA.id)
-					size += 1
-				} else if node.childB != nil {
-					nodeIds = append(nodeIds, node.childB.id)
-					size += 1
-				}
-			} else {
-				break
-			}
-		}
+// place lists the ways of making gate i drive the wire name.
+func (p *repairer) place(r rewiring, i int, name string) []step {
+	if r.gates[i].output == name {
+		return []step{{r, i}}
 	}
+	if s, ok := p.swap(r, i, name); ok {
+		return []step{{s, i}}
+	}
+	return []step{}
 }
 
-func formTree(gates []gate) *tree {
-	t := newTree()
-	for _, gate := range gates {
-		if _, ok := t.nodes[gate.inputA]; !ok {
-			t.addNode(gate.inputA)
-		}
-		if _, ok := t.nodes[gate.inputB]; !ok {
-			t.addNode(gate.inputB)
-		}
-		if _, ok := t.nodes[gate.output]; !ok {
-			t.addNode(gate.output)
+// join lists the ways of finding the gate applying operation to the outputs
+// of gates a and b. When no gate reads both, one output is misnamed, and a
+// gate reading the other shows the name it should have instead.
+func (p *repairer) join(r rewiring, operation string, a, b int) []step {
+	nameA, nameB := r.gates[a].output, r.gates[b].output
+	idsA, othersA := r.readers(operation, nameA)
+	if k := slices.Index(othersA, nameB); k >= 0 {
+		return []step{{r, idsA[k]}}
+	}
+	steps := []step{}
+	for k, other := range othersA {
+		if s, ok := p.swap(r, b, other); ok {
+			steps = append(steps, step{s, idsA[k]})
 		}
 	}
-	for _, gate := range gates {
-		t.addEdge(gate.output, gate.inputA, gate.operation)
-		t.addEdge(gate.output, gate.inputB, gate.operation)
-	}
-	return t
-}
-
-func getIncorrectOutputs(x, y, z int) []string {
-	outputs := []string{}
-	mismatched := z ^ (x + y)
-	for i := 0; mismatched != 0; i++ {
-		if mismatched&1 == 1 {
-			outputs = append(outputs, fmt.Sprintf("z%02d", i))
+	idsB, othersB := r.readers(operation, nameB)
+	for k, other := range othersB {
+		if s, ok := p.swap(r, a, other); ok {
+			steps = append(steps, step{s, idsB[k]})
 		}
-		mismatched >>= 1
 	}
-	return outputs
+	return steps
 }
 
-func swapWires(gates []gate, swaps map[string]string) []gate {
-	if len(swaps) == 0 {
-		return gates
-	}
-	swappedGates := make([]gate, len(gates))
-	for nameA, nameB := range swaps {
-		for i, gate := range gates {
-			if swappedGates[i].operation == "" {
-				swappedGates[i] = gate
-			}
-			if gate.inputA == nameA {
-				swappedGates[i].inputA = nameB
-			}
-			if gate.inputA == nameB {
-				swappedGates[i].inputA = nameA
-			}
-			if gate.inputB == nameA {
-				swappedGates[i].inputB = nameB
-			}
-			if gate.inputB == nameB {
-				swappedGates[i].inputB = nameA
-			}
-			if gate.output == nameA {
-				swappedGates[i].output = nameB
-			}
-			if gate.output == nameB {
-				swappedGates[i].output = nameA
+// repair walks a ripple carry adder from bit i, where each bit computes
+//
+//	p = x XOR y, g = x AND y, z = p XOR c, t = p AND c, c' = t OR g
+//
+// from the gate carry producing the carry c into the bit. Bit 0 has no carry
+// in, so its AND gate is the carry out, and the carry out of the last bit is
+// the final output. Wherever a wire is misnamed the walk tries each swap
+// that puts it back, and it returns the first rewiring that verifies as an
+// adder.
+func (p *repairer) repair(r rewiring, i, carry int) (rewiring, bool) {
+	if i == p.nBits {
+		for _, s := range p.place(r, carry, fmt.Sprintf("z%02d", p.nBits)) {
+			if counterexamples, err := verifyAdder(s.r.gates, p.nBits); err == nil && len(counterexamples) == 0 {
+				return s.r, true
 			}
 		}
+		return r, false
 	}
-	return swappedGates
-}
-
-func swapOutputs(gates []gate, swaps map[string]string) []gate {
-	if len(swaps) == 0 {
-		return gates
+	x, y, z := fmt.Sprintf("x%02d", i), fmt.Sprintf("y%02d", i), fmt.Sprintf("z%02d", i)
+	sum, generate := r.gateOf("XOR", x, y), r.gateOf("AND", x, y)
+	if sum < 0 || generate < 0 {
+		return r, false
 	}
-	swappedGates := make([]gate, len(gates))
-	for nameA, nameB := range swaps {
-		for i, gate := range gates {
-			if swappedGates[i].operation == "" {
-				swappedGates[i] = gate
-			}
-			if gate.output == nameA {
-				swappedGates[i].output = nameB
+	if i == 0 {
+		for _, s := range p.place(r, sum, z) {
+			if repaired, ok := p.repair(s.r, 1, generate); ok {
+				return repaired, true
 			}
-			if gate.output == nameB {
-				swappedGates[i].output = nameA
+		}
+		return r, false
+	}
+	for _, output := range p.join(r, "XOR", sum, carry) {
+		for _, placed := range p.place(output.r, output.gate, z) {
+			for _, propagate := range p.join(placed.r, "AND", sum, carry) {
+				for _, carryOut := range p.join(propagate.r, "OR", propagate.gate, generate) {
+					if repaired, ok := p.repair(carryOut.r, i+1, carryOut.gate); ok {
+						return repaired, true
+					}
+				}
 			}
 		}
 	}
-	return swappedGates
+	return r, false
 }
 
-func FindSwapped(inputs []string) string {
-	initialValues, gates := parseInputs(inputs)
-	wireCounts := map[string]int{}
-	for _, gate := range gates {
-		wireCounts[gate.inputA]++
-		wireCounts[gate.inputB]++
-		wireCounts[gate.output]++
-	}
-	outputWireCounts := map[string]int{}
-	for _, gate := range gates {
-		outputWireCounts[gate.output]++
-	}
-	/*
-		for name, count := range wireCounts {
-			if count == 1 && name[0] != 'z' {
-				log.Printf("Anomolous wire: %s", name)
-			}
+// findSwapped returns the sorted outputs of the fewest swaps that make gates
+// add x and y, raising the limit on swaps until a walk succeeds or is no
+// longer cut short by it.
+func findSwapped(gates []gate) ([]string, error) {
+	nBits := countBits(gates)
+	if nBits == 0 {
+		return nil, fmt.Errorf("circuit has no x inputs")
+	}
+	start := rewiring{gates, []string{}}
+	for maxSwaps := 0; ; maxSwaps++ {
+		p := repairer{nBits: nBits, maxSwaps: maxSwaps}
+		if repaired, ok := p.repair(start, 0, -1); ok {
+			swapped := slices.Clone(repaired.swapped)
+			slices.Sort(swapped)
+			return swapped, nil
+		}
+		if !p.limited {
+			return nil, fmt.Errorf("no swaps of gate outputs make the %d bit circuit an adder", nBits)
 		}
-	*/
-	log.Printf("There are %d gates, %d wires and %d output wires",
-		len(gates), len(wireCounts), len(outputWireCounts),
-	)
-	nBits := len(initialValues) / 2
-	//nBits := 14
-	/*
-		allValid := true
-		validatedWires := map[string]bool{}
-	*/
-	wireNames := []string{}
-	for name := range outputWireCounts {
-		wireNames = append(wireNames, name)
 	}
-	//iSwapLoop:
-	//for iSwap := range wireNames {
-	//jSwapLoop:
-	//for jSwap := range wireNames {
-	//nameA := wireNames[iSwap]
-	//nameB := wireNames[jSwap]
-	swappedGates := swapOutputs(gates, map[string]string{"z12": "z12"})
-	//allCorrect := true
-	//dependencies := formTree(swappedGates)
-	for i := range nBits {
-		for cx := range 2 {
-			for cy := range 2 {
-				x := cx << i
-				y := cy << i
-				initialValues := generateInitalValues(x, y, nBits)
-				wires, e := startGates(swappedGates)
-				z, err := computeResult(wires, initialValues, e)
-				if err != nil {
-					//continue jSwapLoop
-				}
-				if z-(x+y) != 0 {
-					//allCorrect = false
-					//allValid = false
-					log.Printf("Result incorrect\n   %045b\n + %045b\n!= %045b", x, y, z)
-					incorrectOutputs := getIncorrectOutputs(x, y, z)
-					log.Printf("Incorrect outputs: %v", incorrectOutputs)
-					/*
-						dependentWires := map[string]bool{}
-						for _, incorrectOutput := range incorrectOutputs {
-							//log.Printf("Gates for output %s:", incorrectOutput)
-							for wireName, _ := range dependencies.connectedNodes(incorrectOutput) {
-
-									if i < 14 && node.childA != nil {
-										op := dependencies.edgeValues[[2]string{wireName, node.childA.id}]
-										log.Printf("%s %s %s -> %s", node.childA.id, op, node.childB.id, wireName)
-									}
+}
 
-								dependentWires[wireName] = true
-							}
-						}
-						problemWires := []string{}
-						for wireName := range maps.Keys(dependentWires) {
-							if !validatedWires[wireName] {
-								problemWires = append(problemWires, wireName)
-							}
-						}
-						slices.Sort(problemWires)
-						log.Printf("Problem wires: %q", problemWires)
-					*/
-				}
-			}
-		}
-		/*
-			if allValid {
-				for wireName, _ := range dependencies.connectedNodes(fmt.Sprintf("z%02d", i)) {
-					validatedWires[wireName] = true
-				}
-			}
-		*/
+// FindSwapped returns the comma separated outputs that were swapped in a
+// ripple carry adder, or an error if no swaps repair it.
+func FindSwapped(inputs []string) (string, error) {
+	_, gates := parseInputs(inputs)
+	if counterexamples, err := verifyAdder(gates, countBits(gates)); err != nil {
+		log.Printf("Could not verify the adder: %v", err)
+	} else {
+		log.Printf("%d of %d outputs differ from x + y", len(counterexamples), countBits(gates)+1)
 	}
-	/*
-				if allCorrect {
-					log.Printf("All correct for swap %s <-> %s", nameA, nameB)
-					break iSwapLoop
-				}
-			}
-		}
-	*/
-	return ""
+	swapped, err := findSwapped(gates)
+	if err != nil {
+		return "", err
+	}
+	log.Printf("Swapping %d outputs repairs the adder", len(swapped))
+	return strings.Join(swapped, ","), nil
 }
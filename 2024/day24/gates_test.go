@@ -1,6 +1,65 @@
 package day24
 
-import "testing"
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+// adderInputs builds a ripple carry adder of nBits bits with the outputs of
+// each pair in swaps exchanged.
+func adderInputs(nBits int, x, y int, swaps [][2]string) []string {
+	inputs := []string{}
+	for _, name := range []string{"x", "y"} {
+		value := x
+		if name == "y" {
+			value = y
+		}
+		for i := range nBits {
+			inputs = append(inputs, fmt.Sprintf("%s%02d: %d", name, i, value>>i&1))
+		}
+	}
+	inputs = append(inputs, "")
+	swapped := map[string]string{}
+	for _, swap := range swaps {
+		swapped[swap[0]] = swap[1]
+		swapped[swap[1]] = swap[0]
+	}
+	addGate := func(inputA, operation, inputB, output string) {
+		if name, ok := swapped[output]; ok {
+			output = name
+		}
+		inputs = append(inputs, fmt.Sprintf("%s %s %s -> %s", inputA, operation, inputB, output))
+	}
+	carry := ""
+	for i := range nBits {
+		x, y := fmt.Sprintf("x%02d", i), fmt.Sprintf("y%02d", i)
+		z := fmt.Sprintf("z%02d", i)
+		p, g := fmt.Sprintf("p%02d", i), fmt.Sprintf("g%02d", i)
+		if i == nBits-1 {
+			g = fmt.Sprintf("z%02d", nBits)
+		}
+		if i == 0 {
+			addGate(x, "XOR", y, z)
+			addGate(y, "AND", x, g)
+			carry = g
+			continue
+		}
+		t, c := fmt.Sprintf("t%02d", i), fmt.Sprintf("c%02d", i)
+		if i == nBits-1 {
+			g = fmt.Sprintf("g%02d", i)
+			c = fmt.Sprintf("z%02d", nBits)
+		}
+		addGate(x, "XOR", y, p)
+		addGate(y, "AND", x, g)
+		addGate(carry, "XOR", p, z)
+		addGate(p, "AND", carry, t)
+		addGate(g, "OR", t, c)
+		carry = c
+	}
+	return inputs
+}
 
 func TestEvaluate(t *testing.T) {
 	cases := []struct {
@@ -74,6 +133,18 @@ func TestEvaluate(t *testing.T) {
 			},
 			expected: 2024,
 		},
+		{
+			inputs: []string{
+				"x00: 1",
+				"y00: 0",
+				"",
+				"x00 NAND y00 -> z00",
+				"NOT x00 -> z01",
+				"x00 XNOR y00 -> z02",
+				"y00 NOR y00 -> z03",
+			},
+			expected: 9,
+		},
 	}
 	for _, c := range cases {
 		result := Evaluate(c.inputs)
@@ -84,3 +155,123 @@ func TestEvaluate(t *testing.T) {
 		}
 	}
 }
+
+func TestEvaluateAdder(t *testing.T) {
+	cases := []struct {
+		nBits, x, y int
+	}{
+		{1, 1, 1},
+		{2, 3, 1},
+		{8, 200, 100},
+		{16, 0xffff, 0xffff},
+		{45, 1<<44 + 12345, 1<<43 + 54321},
+	}
+	for _, c := range cases {
+		result := Evaluate(adderInputs(c.nBits, c.x, c.y, nil))
+		if result != c.x+c.y {
+			t.Errorf("Evaluate(%d bit adder, %d, %d) == %d, expected %d",
+				c.nBits, c.x, c.y, result, c.x+c.y,
+			)
+		}
+	}
+}
+
+func TestFindSwapped(t *testing.T) {
+	cases := []struct {
+		nBits    int
+		swaps    [][2]string
+		expected string
+	}{
+		{8, nil, ""},
+		{1, nil, ""},
+		{8, [][2]string{{"z03", "c03"}}, "c03,z03"},
+		{8, [][2]string{{"p05", "g05"}}, "g05,p05"},
+		{8, [][2]string{{"z02", "t02"}, {"p06", "z06"}}, "p06,t02,z02,z06"},
+		{
+			45,
+			[][2]string{{"z12", "c12"}, {"z21", "t21"}, {"p30", "g30"}, {"z33", "g33"}},
+			"c12,g30,g33,p30,t21,z12,z21,z33",
+		},
+		{16, [][2]string{{"z16", "g03"}}, "g03,z16"},
+		{16, [][2]string{{"g00", "z00"}}, "g00,z00"},
+		{8, [][2]string{{"t03", "g04"}}, "g04,t03"},
+		{12, [][2]string{{"z03", "z08"}}, "z03,z08"},
+		{12, [][2]string{{"p01", "p02"}}, "p01,p02"},
+		{12, [][2]string{{"g07", "t02"}}, "g07,t02"},
+		{12, [][2]string{{"g11", "t09"}}, "g11,t09"},
+		{12, [][2]string{{"p10", "p11"}}, "p10,p11"},
+		{12, [][2]string{{"c04", "p09"}, {"z05", "g01"}}, "c04,g01,p09,z05"},
+	}
+	for _, c := range cases {
+		result, err := FindSwapped(adderInputs(c.nBits, 0, 0, c.swaps))
+		if err != nil {
+			t.Errorf("FindSwapped(%d bit adder with swaps %v) returned error %v", c.nBits, c.swaps, err)
+		} else if result != c.expected {
+			t.Errorf("FindSwapped(%d bit adder with swaps %v) == %q, expected %q",
+				c.nBits, c.swaps, result, c.expected,
+			)
+		}
+	}
+}
+
+func TestFindSwappedRandomSwaps(t *testing.T) {
+	r := rand.New(rand.NewSource(24))
+	_, gates := parseInputs(adderInputs(12, 0, 0, nil))
+	for range 300 {
+		i, j := r.Intn(len(gates)), r.Intn(len(gates))
+		if i == j {
+			continue
+		}
+		swap := [2]string{gates[i].output, gates[j].output}
+		inputs := adderInputs(12, 0, 0, [][2]string{swap})
+		_, swappedGates := parseInputs(inputs)
+		if counterexamples, err := verifyAdder(swappedGates, 12); err == nil && len(counterexamples) == 0 {
+			continue
+		}
+		result, err := FindSwapped(inputs)
+		if err != nil {
+			t.Errorf("FindSwapped(12 bit adder with swap %v) returned error %v", swap, err)
+			continue
+		}
+		names := strings.Split(result, ",")
+		if len(names) != 2 {
+			t.Errorf("FindSwapped(12 bit adder with swap %v) == %q, expected one pair", swap, result)
+			continue
+		}
+		for k, g := range swappedGates {
+			switch g.output {
+			case names[0]:
+				swappedGates[k].output = names[1]
+			case names[1]:
+				swappedGates[k].output = names[0]
+			}
+		}
+		if counterexamples, err := verifyAdder(swappedGates, 12); err != nil || len(counterexamples) > 0 {
+			t.Errorf("FindSwapped(12 bit adder with swap %v) == %q, which does not repair it", swap, result)
+		}
+	}
+}
+
+func TestFindSwappedUnrepairable(t *testing.T) {
+	cases := []struct {
+		name   string
+		suffix string
+		from   string
+		to     string
+	}{
+		{"z05 from an AND gate", "-> z05", "XOR", "AND"},
+		{"p04 from an OR gate", "-> p04", "XOR", "OR"},
+		{"c06 from an AND gate", "-> c06", "OR", "AND"},
+	}
+	for _, c := range cases {
+		inputs := adderInputs(8, 0, 0, nil)
+		for i, s := range inputs {
+			if strings.HasSuffix(s, c.suffix) {
+				inputs[i] = strings.Replace(s, c.from, c.to, 1)
+			}
+		}
+		if result, err := FindSwapped(inputs); err == nil {
+			t.Errorf("FindSwapped(8 bit adder with %s) == %q, expected an error", c.name, result)
+		}
+	}
+}
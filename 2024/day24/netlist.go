@@ -0,0 +1,283 @@
+package day24
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+var verilogPrimitives = map[string]string{
+	"AND":  "and",
+	"OR":   "or",
+	"XOR":  "xor",
+	"NAND": "nand",
+	"NOR":  "nor",
+	"XNOR": "xnor",
+	"NOT":  "not",
+}
+
+var verilogKeywords = map[string]bool{
+	"and": true, "bit": true, "buf": true, "end": true, "for": true,
+	"int": true, "let": true, "new": true, "nor": true, "not": true,
+	"or": true, "ref": true, "reg": true, "tri": true, "use": true,
+	"var": true, "wor": true, "xor": true,
+}
+
+type ports struct {
+	inputs, outputs, internal []string
+}
+
+// classifyWires splits wires into inputs, which no gate drives, outputs,
+// which are z wires or read by no gate, and internal wires.
+func classifyWires(gates []gate) ports {
+	driven, read := map[string]bool{}, map[string]bool{}
+	for _, g := range gates {
+		driven[g.output] = true
+		read[g.inputA] = true
+		if g.inputB != "" {
+			read[g.inputB] = true
+		}
+	}
+	p := ports{}
+	for name := range read {
+		if !driven[name] {
+			p.inputs = append(p.inputs, name)
+		}
+	}
+	for name := range driven {
+		if isOutputWire(name) || !read[name] {
+			p.outputs = append(p.outputs, name)
+		} else {
+			p.internal = append(p.internal, name)
+		}
+	}
+	slices.Sort(p.inputs)
+	slices.Sort(p.outputs)
+	slices.Sort(p.internal)
+	return p
+}
+
+func findSuspects(gates []gate) map[string]bool {
+	suspects := map[string]bool{}
+	if swapped, err := findSwapped(gates); err == nil {
+		for _, name := range swapped {
+			suspects[name] = true
+		}
+	}
+	return suspects
+}
+
+func writeDOT(w io.Writer, gates []gate, suspects map[string]bool) error {
+	p := classifyWires(gates)
+	b := bufio.NewWriter(w)
+	fmt.Fprintln(b, "digraph circuit {")
+	fmt.Fprintln(b, "  rankdir=LR;")
+	fmt.Fprintln(b, "  node [shape=ellipse];")
+	fmt.Fprintln(b, "  { rank=source;")
+	for _, name := range p.inputs {
+		fmt.Fprintf(b, "    %q [shape=box, style=filled, fillcolor=lightblue];\n", name)
+	}
+	fmt.Fprintln(b, "  }")
+	fmt.Fprintln(b, "  { rank=sink;")
+	for _, name := range p.outputs {
+		fmt.Fprintf(b, "    %q [shape=box, style=filled, fillcolor=palegreen];\n", name)
+	}
+	fmt.Fprintln(b, "  }")
+	for i, g := range gates {
+		gateId := fmt.Sprintf("g%d", i)
+		attributes := ""
+		if suspects[g.output] {
+			attributes = ", style=filled, fillcolor=salmon"
+			fmt.Fprintf(b, "  %q [color=red];\n", g.output)
+		}
+		fmt.Fprintf(b, "  %s [label=%q, shape=invhouse%s];\n", gateId, g.operation, attributes)
+		fmt.Fprintf(b, "  %q -> %s;\n", g.inputA, gateId)
+		if g.inputB != "" {
+			fmt.Fprintf(b, "  %q -> %s;\n", g.inputB, gateId)
+		}
+		fmt.Fprintf(b, "  %s -> %q;\n", gateId, g.output)
+	}
+	fmt.Fprintln(b, "}")
+	return b.Flush()
+}
+
+func verilogName(name string) string {
+	if verilogKeywords[name] {
+		return `\` + name + " "
+	}
+	return name
+}
+
+func verilogNames(names []string) string {
+	escaped := make([]string, len(names))
+	for i, name := range names {
+		escaped[i] = verilogName(name)
+	}
+	return strings.Join(escaped, ", ")
+}
+
+func writeVerilog(w io.Writer, gates []gate, module string) error {
+	p := classifyWires(gates)
+	b := bufio.NewWriter(w)
+	fmt.Fprintf(b, "module %s (%s);\n", module, verilogNames(slices.Concat(p.inputs, p.outputs)))
+	if len(p.inputs) > 0 {
+		fmt.Fprintf(b, "  input %s;\n", verilogNames(p.inputs))
+	}
+	if len(p.outputs) > 0 {
+		fmt.Fprintf(b, "  output %s;\n", verilogNames(p.outputs))
+	}
+	if len(p.internal) > 0 {
+		fmt.Fprintf(b, "  wire %s;\n", verilogNames(p.internal))
+	}
+	for i, g := range gates {
+		primitive, ok := verilogPrimitives[g.operation]
+		if !ok {
+			return fmt.Errorf("gate %d has unsupported operation %q", i, g.operation)
+		}
+		terminals := []string{g.output, g.inputA}
+		if g.inputB != "" {
+			terminals = append(terminals, g.inputB)
+		}
+		fmt.Fprintf(b, "  %s g%d (%s);\n", primitive, i, verilogNames(terminals))
+	}
+	fmt.Fprintln(b, "endmodule")
+	return b.Flush()
+}
+
+var (
+	verilogComment    = regexp.MustCompile(`(?s)//[^\n]*|/\*.*?\*/`)
+	verilogIdentifier = regexp.MustCompile(`^(?:\\(\S+)|([A-Za-z_][A-Za-z0-9_$]*))$`)
+	verilogStatement  = regexp.MustCompile(`(?s)^([a-z]+)\s*(\\\S+\s|[A-Za-z_][A-Za-z0-9_$]*)?\s*(?:\((.*)\))?\s*(.*)$`)
+	wireName          = regexp.MustCompile(`^[a-z][0-9a-z]{2}$`)
+)
+
+func parseWireNames(list string) ([]string, error) {
+	names := []string{}
+	for _, part := range strings.Split(list, ",") {
+		part = strings.TrimSpace(part)
+		match := verilogIdentifier.FindStringSubmatch(part)
+		if match == nil {
+			return nil, fmt.Errorf("invalid identifier %q", part)
+		}
+		name := match[1] + match[2]
+		if !wireName.MatchString(name) {
+			return nil, fmt.Errorf("wire name %q does not have the form of a puzzle wire", name)
+		}
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+// parseVerilog reads a single structural Verilog module made of gate
+// primitive instances, as written by writeVerilog, back into gates.
+func parseVerilog(r io.Reader) ([]gate, error) {
+	source, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	text := verilogComment.ReplaceAllString(string(source), " ")
+	text = strings.TrimSpace(text)
+	if !strings.HasSuffix(text, "endmodule") {
+		return nil, fmt.Errorf("missing endmodule")
+	}
+	text = strings.TrimSuffix(text, "endmodule")
+
+	operations := map[string]string{}
+	for operation, primitive := range verilogPrimitives {
+		operations[primitive] = operation
+	}
+	gates := []gate{}
+	inModule := false
+	for _, statement := range strings.Split(text, ";") {
+		statement = strings.TrimSpace(statement)
+		if statement == "" {
+			continue
+		}
+		keyword := strings.Fields(statement)[0]
+		switch keyword {
+		case "input", "output", "wire":
+			if !inModule {
+				return nil, fmt.Errorf("statement %q outside of module", statement)
+			}
+			if _, err := parseWireNames(strings.TrimPrefix(statement, keyword)); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		match := verilogStatement.FindStringSubmatch(statement)
+		if match == nil || match[4] != "" {
+			return nil, fmt.Errorf("unsupported statement %q", statement)
+		}
+		keyword = match[1]
+		if keyword == "module" {
+			if inModule {
+				return nil, fmt.Errorf("nested module in %q", statement)
+			}
+			inModule = true
+			continue
+		}
+		if !inModule {
+			return nil, fmt.Errorf("statement %q outside of module", statement)
+		}
+		names, err := parseWireNames(match[3])
+		if err != nil {
+			return nil, err
+		}
+		operation, ok := operations[keyword]
+		if !ok {
+			return nil, fmt.Errorf("unsupported primitive %q", keyword)
+		}
+		switch {
+		case operation == "NOT" && len(names) == 2:
+			gates = append(gates, gate{operation, names[1], "", names[0]})
+		case operation != "NOT" && len(names) == 3:
+			gates = append(gates, gate{operation, names[1], names[2], names[0]})
+		default:
+			return nil, fmt.Errorf("%s gate with %d terminals is not supported", keyword, len(names))
+		}
+	}
+	if !inModule {
+		return nil, fmt.Errorf("missing module")
+	}
+	return gates, nil
+}
+
+func formatGate(g gate) string {
+	if g.operation == "NOT" {
+		return fmt.Sprintf("NOT %s -> %s", g.inputA, g.output)
+	}
+	return fmt.Sprintf("%s %s %s -> %s", g.inputA, g.operation, g.inputB, g.output)
+}
+
+// ExportDOT writes the circuit as a Graphviz graph, highlighting the gates
+// whose outputs must be swapped to repair the ripple carry adder.
+func ExportDOT(inputs []string, w io.Writer) error {
+	_, gates := parseInputs(inputs)
+	return writeDOT(w, gates, findSuspects(gates))
+}
+
+func ExportVerilog(inputs []string, w io.Writer) error {
+	_, gates := parseInputs(inputs)
+	return writeVerilog(w, gates, "circuit")
+}
+
+// ImportVerilog converts a structural Verilog module into puzzle input, with
+// every input wire initially 0.
+func ImportVerilog(r io.Reader) ([]string, error) {
+	gates, err := parseVerilog(r)
+	if err != nil {
+		return nil, err
+	}
+	lines := []string{}
+	for _, name := range classifyWires(gates).inputs {
+		lines = append(lines, fmt.Sprintf("%s: 0", name))
+	}
+	lines = append(lines, "")
+	for _, g := range gates {
+		lines = append(lines, formatGate(g))
+	}
+	return lines, nil
+}
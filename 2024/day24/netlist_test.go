@@ -0,0 +1,146 @@
+package day24
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestExportDOT(t *testing.T) {
+	inputs := adderInputs(3, 0, 0, [][2]string{{"z01", "t01"}})
+	var b bytes.Buffer
+	if err := ExportDOT(inputs, &b); err != nil {
+		t.Fatalf("ExportDOT(%q) returned error %v", inputs, err)
+	}
+	dot := b.String()
+	expected := []string{
+		"digraph circuit {",
+		`"x00" [shape=box, style=filled, fillcolor=lightblue];`,
+		`"z03" [shape=box, style=filled, fillcolor=palegreen];`,
+		`g0 [label="XOR", shape=invhouse];`,
+		`"x00" -> g0;`,
+		`g0 -> "z00";`,
+		`"z01" [color=red];`,
+		`"t01" [color=red];`,
+	}
+	for _, line := range expected {
+		if !strings.Contains(dot, line) {
+			t.Errorf("ExportDOT(%q) does not contain %q:\n%s", inputs, line, dot)
+		}
+	}
+	if strings.Contains(dot, `"p01" [color=red]`) {
+		t.Errorf("ExportDOT(%q) highlights p01:\n%s", inputs, dot)
+	}
+}
+
+func TestExportVerilog(t *testing.T) {
+	inputs := []string{
+		"x00: 1",
+		"y00: 0",
+		"",
+		"x00 AND y00 -> and",
+		"NOT and -> z00",
+		"x00 XNOR and -> z01",
+	}
+	expected := strings.Join([]string{
+		"module circuit (x00, y00, z00, z01);",
+		"  input x00, y00;",
+		"  output z00, z01;",
+		`  wire \and ;`,
+		`  and g0 (\and , x00, y00);`,
+		`  not g1 (z00, \and );`,
+		`  xnor g2 (z01, x00, \and );`,
+		"endmodule",
+		"",
+	}, "\n")
+	var b bytes.Buffer
+	if err := ExportVerilog(inputs, &b); err != nil {
+		t.Fatalf("ExportVerilog(%q) returned error %v", inputs, err)
+	}
+	if b.String() != expected {
+		t.Errorf("ExportVerilog(%q) ==\n%s\nexpected\n%s", inputs, b.String(), expected)
+	}
+}
+
+func TestImportVerilog(t *testing.T) {
+	source := `
+// hand written half adder
+module half_adder(x00, y00, z00, z01);
+  input x00, y00;
+  output z00,
+         z01;
+  /* sum and carry */
+  xor (z00, x00, y00);
+  and carry (z01, x00, y00);
+endmodule
+`
+	expected := []string{
+		"x00: 0",
+		"y00: 0",
+		"",
+		"x00 XOR y00 -> z00",
+		"x00 AND y00 -> z01",
+	}
+	result, err := ImportVerilog(strings.NewReader(source))
+	if err != nil || !slices.Equal(result, expected) {
+		t.Errorf("ImportVerilog(%q) == %q, %v, expected %q", source, result, err, expected)
+	}
+}
+
+func TestVerilogRoundTrip(t *testing.T) {
+	inputs := adderInputs(8, 0, 0, [][2]string{{"p03", "g03"}})
+	var b bytes.Buffer
+	if err := ExportVerilog(inputs, &b); err != nil {
+		t.Fatalf("ExportVerilog(8 bit adder) returned error %v", err)
+	}
+	imported, err := ImportVerilog(&b)
+	if err != nil {
+		t.Fatalf("ImportVerilog(8 bit adder) returned error %v", err)
+	}
+	_, expected := parseInputs(inputs)
+	_, result := parseInputs(imported)
+	if !slices.Equal(result, expected) {
+		t.Errorf("ImportVerilog(ExportVerilog(8 bit adder)) == %v, expected %v", result, expected)
+	}
+	if swapped, err := FindSwapped(imported); err != nil || swapped != "g03,p03" {
+		t.Errorf("FindSwapped(imported 8 bit adder) == %q, %v, expected \"g03,p03\"", swapped, err)
+	}
+}
+
+func TestImportVerilogErrors(t *testing.T) {
+	cases := []struct {
+		source   string
+		expected string
+	}{
+		{"module m (a01); input a01;", "missing endmodule"},
+		{"and (z00, x00, y00); endmodule", "outside of module"},
+		{"module m (); and (z00, x00, y00, w00); endmodule", "and gate with 4 terminals is not supported"},
+		{"module m (); buf (z00, x00); endmodule", `unsupported primitive "buf"`},
+		{"module m (); input long_name; endmodule", `wire name "long_name"`},
+		{"module m (); assign z00 = x00; endmodule", "unsupported statement"},
+	}
+	for _, c := range cases {
+		_, err := ImportVerilog(strings.NewReader(c.source))
+		if err == nil || !strings.Contains(err.Error(), c.expected) {
+			t.Errorf("ImportVerilog(%q) returned error %v, expected %q", c.source, err, c.expected)
+		}
+	}
+}
+
+func TestVerilogName(t *testing.T) {
+	cases := []struct {
+		name, expected string
+	}{
+		{"x00", "x00"},
+		{"and", `\and `},
+		{"wor", `\wor `},
+		{"tri", `\tri `},
+		{"wrz", "wrz"},
+	}
+	for _, c := range cases {
+		if result := verilogName(c.name); result != c.expected {
+			t.Errorf("verilogName(%q) == %q, expected %q", c.name, result, c.expected)
+		}
+	}
+}
@@ -0,0 +1,277 @@
+package day24
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+type compiledGate struct {
+	operation              string
+	inputA, inputB, output int
+	level                  int
+}
+
+func (g compiledGate) eval(values []uint64) uint64 {
+	a, b := values[g.inputA], uint64(0)
+	if g.inputB >= 0 {
+		b = values[g.inputB]
+	}
+	switch g.operation {
+	case "AND":
+		return a & b
+	case "OR":
+		return a | b
+	case "XOR":
+		return a ^ b
+	case "NAND":
+		return ^(a & b)
+	case "NOR":
+		return ^(a | b)
+	case "XNOR":
+		return ^(a ^ b)
+	case "NOT":
+		return ^a
+	}
+	panic(fmt.Sprintf("unknown gate operation %q", g.operation))
+}
+
+// circuit is a netlist compiled into topological order, with every wire
+// numbered so that simulation works on slices rather than maps.
+type circuit struct {
+	names   []string
+	wireIds map[string]int
+	gates   []compiledGate
+	drivers []int
+	fanout  [][]int
+	inputs  []int
+	nLevels int
+}
+
+func (c *circuit) wireId(name string) int {
+	if name == "" {
+		return -1
+	}
+	id, ok := c.wireIds[name]
+	if !ok {
+		id = len(c.names)
+		c.names = append(c.names, name)
+		c.wireIds[name] = id
+		c.drivers = append(c.drivers, -1)
+		c.fanout = append(c.fanout, []int{})
+	}
+	return id
+}
+
+func compile(gates []gate) (*circuit, error) {
+	c := &circuit{wireIds: map[string]int{}}
+	unordered := make([]compiledGate, len(gates))
+	for i, g := range gates {
+		cg := compiledGate{g.operation, c.wireId(g.inputA), c.wireId(g.inputB), c.wireId(g.output), 0}
+		if c.drivers[cg.output] >= 0 {
+			return nil, fmt.Errorf("wire %s is driven by more than one gate", g.output)
+		}
+		c.drivers[cg.output] = i
+		unordered[i] = cg
+	}
+
+	pending := make([]int, len(gates))
+	for i, g := range unordered {
+		for _, input := range []int{g.inputA, g.inputB} {
+			if input < 0 {
+				continue
+			}
+			if c.drivers[input] >= 0 {
+				pending[i]++
+			}
+		}
+	}
+	queue := []int{}
+	for i := range unordered {
+		if pending[i] == 0 {
+			queue = append(queue, i)
+		}
+	}
+	order := make([]int, 0, len(gates))
+	readers := make([][]int, len(c.names))
+	for i, g := range unordered {
+		for _, input := range []int{g.inputA, g.inputB} {
+			if input >= 0 {
+				readers[input] = append(readers[input], i)
+			}
+		}
+	}
+	for len(queue) > 0 {
+		i := queue[0]
+		queue = queue[1:]
+		order = append(order, i)
+		for _, j := range readers[unordered[i].output] {
+			unordered[j].level = max(unordered[j].level, unordered[i].level+1)
+			pending[j]--
+			if pending[j] == 0 {
+				queue = append(queue, j)
+			}
+		}
+	}
+	if len(order) < len(gates) {
+		return nil, c.describeLoop(unordered, pending)
+	}
+
+	position := make([]int, len(gates))
+	c.gates = make([]compiledGate, len(gates))
+	for k, i := range order {
+		position[i] = k
+		c.gates[k] = unordered[i]
+		c.nLevels = max(c.nLevels, unordered[i].level+1)
+	}
+	for id := range c.drivers {
+		if c.drivers[id] < 0 {
+			c.inputs = append(c.inputs, id)
+		} else {
+			c.drivers[id] = position[c.drivers[id]]
+		}
+		for _, i := range readers[id] {
+			c.fanout[id] = append(c.fanout[id], position[i])
+		}
+	}
+	return c, nil
+}
+
+// describeLoop follows undriven inputs back from a gate that never became
+// ready until a wire repeats, and reports the wires around that loop.
+func (c *circuit) describeLoop(gates []compiledGate, pending []int) error {
+	i := slices.IndexFunc(pending, func(n int) bool { return n > 0 })
+	seen := map[int]int{}
+	path := []int{}
+	for {
+		if start, ok := seen[i]; ok {
+			names := []string{}
+			for _, j := range path[start:] {
+				names = append(names, c.names[gates[j].output])
+			}
+			names = append(names, names[0])
+			return fmt.Errorf("combinational loop through wires %s", strings.Join(names, " -> "))
+		}
+		seen[i] = len(path)
+		path = append(path, i)
+		for _, input := range []int{gates[i].inputA, gates[i].inputB} {
+			if input >= 0 && c.drivers[input] >= 0 && pending[c.drivers[input]] > 0 {
+				i = c.drivers[input]
+				break
+			}
+		}
+	}
+}
+
+// simulator evaluates a circuit on 64 input vectors at once, one per bit of
+// each wire value. After the first run only gates downstream of changed
+// wires are evaluated again.
+type simulator struct {
+	c         *circuit
+	values    []uint64
+	isSet     []bool
+	scheduled []bool
+	levels    [][]int
+}
+
+func (c *circuit) newSimulator() *simulator {
+	s := &simulator{
+		c:         c,
+		values:    make([]uint64, len(c.names)),
+		isSet:     make([]bool, len(c.names)),
+		scheduled: make([]bool, len(c.gates)),
+		levels:    make([][]int, c.nLevels),
+	}
+	for i := range c.gates {
+		s.schedule(i)
+	}
+	return s
+}
+
+func (s *simulator) schedule(i int) {
+	if !s.scheduled[i] {
+		s.scheduled[i] = true
+		level := s.c.gates[i].level
+		s.levels[level] = append(s.levels[level], i)
+	}
+}
+
+func (s *simulator) set(name string, value uint64) error {
+	id, ok := s.c.wireIds[name]
+	if !ok {
+		return fmt.Errorf("unknown wire %s", name)
+	}
+	if s.c.drivers[id] >= 0 {
+		return fmt.Errorf("wire %s is driven by a gate", name)
+	}
+	s.isSet[id] = true
+	if s.values[id] != value {
+		s.values[id] = value
+		for _, i := range s.c.fanout[id] {
+			s.schedule(i)
+		}
+	}
+	return nil
+}
+
+func (s *simulator) get(name string) (uint64, bool) {
+	id, ok := s.c.wireIds[name]
+	if !ok {
+		return 0, false
+	}
+	return s.values[id], true
+}
+
+func (s *simulator) run() error {
+	for _, id := range s.c.inputs {
+		if !s.isSet[id] {
+			return fmt.Errorf("input wire %s has no value", s.c.names[id])
+		}
+	}
+	for level := range s.levels {
+		for k := 0; k < len(s.levels[level]); k++ {
+			i := s.levels[level][k]
+			s.scheduled[i] = false
+			g := s.c.gates[i]
+			if value := g.eval(s.values); value != s.values[g.output] {
+				s.values[g.output] = value
+				for _, j := range s.c.fanout[g.output] {
+					s.schedule(j)
+				}
+			}
+		}
+		s.levels[level] = s.levels[level][:0]
+	}
+	return nil
+}
+
+// setOperand spreads up to 64 numbers over the wires prefix00, prefix01, ...
+// with number k in bit k of each wire.
+func (s *simulator) setOperand(prefix string, nBits int, numbers []int) error {
+	for i := range nBits {
+		word := uint64(0)
+		for k, n := range numbers {
+			word |= uint64(n>>i&1) << k
+		}
+		if err := s.set(fmt.Sprintf("%s%02d", prefix, i), word); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// getOperand reads back the numbers spread over the wires prefix00,
+// prefix01, ... for the first count vectors.
+func (s *simulator) getOperand(prefix string, count int) []int {
+	numbers := make([]int, count)
+	for i := 0; ; i++ {
+		word, ok := s.get(fmt.Sprintf("%s%02d", prefix, i))
+		if !ok {
+			break
+		}
+		for k := range numbers {
+			numbers[k] |= int(word>>k&1) << i
+		}
+	}
+	return numbers
+}
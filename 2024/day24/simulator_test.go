@@ -0,0 +1,131 @@
+package day24
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSimulatorGates(t *testing.T) {
+	gates := []gate{
+		{"AND", "aaa", "bbb", "and"},
+		{"OR", "aaa", "bbb", "ora"},
+		{"XOR", "aaa", "bbb", "xor"},
+		{"NAND", "aaa", "bbb", "nnd"},
+		{"NOR", "aaa", "bbb", "nor"},
+		{"XNOR", "aaa", "bbb", "xnr"},
+		{"NOT", "aaa", "", "not"},
+	}
+	c, err := compile(gates)
+	if err != nil {
+		t.Fatalf("compile(%v) returned error %v", gates, err)
+	}
+	s := c.newSimulator()
+	s.set("aaa", 0b1100)
+	s.set("bbb", 0b1010)
+	if err := s.run(); err != nil {
+		t.Fatalf("run() returned error %v", err)
+	}
+	cases := []struct {
+		name     string
+		expected uint64
+	}{
+		{"and", 0b1000},
+		{"ora", 0b1110},
+		{"xor", 0b0110},
+		{"nnd", ^uint64(0b1000)},
+		{"nor", ^uint64(0b1110)},
+		{"xnr", ^uint64(0b0110)},
+		{"not", ^uint64(0b1100)},
+	}
+	for _, c := range cases {
+		result, _ := s.get(c.name)
+		if result != c.expected {
+			t.Errorf("get(%q) == %b, expected %b", c.name, result, c.expected)
+		}
+	}
+}
+
+func TestSimulatorEvents(t *testing.T) {
+	_, gates := parseInputs(adderInputs(8, 0, 0, nil))
+	c, err := compile(gates)
+	if err != nil {
+		t.Fatalf("compile(8 bit adder) returned error %v", err)
+	}
+	s := c.newSimulator()
+	xs := []int{0, 1, 255, 200, 17}
+	ys := []int{0, 255, 255, 100, 0}
+	for step := range 3 {
+		s.setOperand("x", 8, xs)
+		s.setOperand("y", 8, ys)
+		if err := s.run(); err != nil {
+			t.Fatalf("run() returned error %v", err)
+		}
+		for k, z := range s.getOperand("z", len(xs)) {
+			if z != xs[k]+ys[k] {
+				t.Errorf("step %d: %d + %d == %d, expected %d", step, xs[k], ys[k], z, xs[k]+ys[k])
+			}
+		}
+		xs[step], ys[step] = ys[step+1], xs[step+2]
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	cases := []struct {
+		gates    []gate
+		expected string
+	}{
+		{
+			[]gate{
+				{"AND", "x00", "ccc", "aaa"},
+				{"OR", "aaa", "y00", "bbb"},
+				{"XOR", "bbb", "x00", "ccc"},
+				{"AND", "ccc", "y00", "z00"},
+			},
+			"combinational loop through wires",
+		},
+		{
+			[]gate{
+				{"AND", "x00", "y00", "z00"},
+				{"OR", "x00", "y00", "z00"},
+			},
+			"wire z00 is driven by more than one gate",
+		},
+	}
+	for _, c := range cases {
+		_, err := compile(c.gates)
+		if err == nil || !strings.Contains(err.Error(), c.expected) {
+			t.Errorf("compile(%v) returned error %v, expected %q", c.gates, err, c.expected)
+		}
+	}
+
+	_, err := compile(cases[0].gates)
+	for _, name := range []string{"aaa", "bbb", "ccc"} {
+		if err == nil || !strings.Contains(err.Error(), name) {
+			t.Errorf("compile(%v) returned error %v, expected it to name %s", cases[0].gates, err, name)
+		}
+	}
+}
+
+func TestRunMissingInput(t *testing.T) {
+	c, _ := compile([]gate{{"AND", "x00", "y00", "z00"}})
+	s := c.newSimulator()
+	s.set("x00", 1)
+	if err := s.run(); err == nil || err.Error() != "input wire y00 has no value" {
+		t.Errorf("run() returned error %v, expected \"input wire y00 has no value\"", err)
+	}
+}
+
+func BenchmarkSimulator(b *testing.B) {
+	_, gates := parseInputs(adderInputs(45, 0, 0, nil))
+	c, _ := compile(gates)
+	s := c.newSimulator()
+	xs, ys := make([]int, 64), make([]int, 64)
+	for i := range b.N {
+		for k := range xs {
+			xs[k], ys[k] = (i*64+k)*7919, (i*64+k)*104729
+		}
+		s.setOperand("x", 45, xs)
+		s.setOperand("y", 45, ys)
+		s.run()
+	}
+}
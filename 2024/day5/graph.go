@@ -0,0 +1,207 @@
+package day5
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+func (r Rule) String() string {
+	return fmt.Sprintf("%d|%d", r.before, r.after)
+}
+
+// RuleGraph has an edge from each page to every page a rule says must come
+// after it.
+type RuleGraph struct {
+	after map[int][]int
+}
+
+func NewRuleGraph(rules []Rule) RuleGraph {
+	after := make(map[int][]int)
+	for _, r := range rules {
+		if !slices.Contains(after[r.before], r.after) {
+			after[r.before] = append(after[r.before], r.after)
+		}
+	}
+	return RuleGraph{after}
+}
+
+// successors lists the pages that must follow page among those in index.
+func (g RuleGraph) successors(page int, index map[int]int) []int {
+	pages := []int{}
+	for _, next := range g.after[page] {
+		if _, ok := index[next]; ok {
+			pages = append(pages, next)
+		}
+	}
+	return pages
+}
+
+// CycleError reports the groups of rules that contradict each other among
+// the pages of an update.
+type CycleError struct {
+	Cycles [][]Rule
+}
+
+func (e *CycleError) Error() string {
+	groups := make([]string, len(e.Cycles))
+	for i, rules := range e.Cycles {
+		parts := make([]string, len(rules))
+		for j, r := range rules {
+			parts[j] = r.String()
+		}
+		groups[i] = strings.Join(parts, " ")
+	}
+	return "cyclic rules: " + strings.Join(groups, "; ")
+}
+
+// Order sorts the pages with Kahn's algorithm using only the rules between
+// them. Of the pages free to go next, the one earliest in the input goes
+// first, so pages that already satisfy the rules keep their order.
+func (g RuleGraph) Order(pages []int) ([]int, error) {
+	index := make(map[int]int, len(pages))
+	for i, page := range pages {
+		index[page] = i
+	}
+	inDegree := make([]int, len(pages))
+	for _, page := range pages {
+		for _, next := range g.successors(page, index) {
+			inDegree[index[next]]++
+		}
+	}
+	order := make([]int, 0, len(pages))
+	placed := make([]bool, len(pages))
+	for len(order) < len(pages) {
+		// Updates are short, so scanning for the next free page is cheaper
+		// than keeping a priority queue.
+		i := 0
+		for i < len(pages) && (placed[i] || inDegree[i] > 0) {
+			i++
+		}
+		if i == len(pages) {
+			return nil, &CycleError{g.CyclicRules(pages)}
+		}
+		placed[i] = true
+		order = append(order, pages[i])
+		for _, next := range g.successors(pages[i], index) {
+			inDegree[index[next]]--
+		}
+	}
+	return order, nil
+}
+
+// CyclicRules finds the strongly connected groups of pages with Tarjan's
+// algorithm and returns the rules inside each group with more than one page,
+// or with a page that must come after itself.
+func (g RuleGraph) CyclicRules(pages []int) [][]Rule {
+	index := make(map[int]int, len(pages))
+	for i, page := range pages {
+		index[page] = i
+	}
+	const unvisited = -1
+	order := slices.Repeat([]int{unvisited}, len(pages))
+	low := make([]int, len(pages))
+	onStack := make([]bool, len(pages))
+	stack := []int{}
+	counter := 0
+	cycles := [][]Rule{}
+
+	var visit func(i int)
+	visit = func(i int) {
+		order[i], low[i] = counter, counter
+		counter++
+		stack = append(stack, i)
+		onStack[i] = true
+		for _, next := range g.successors(pages[i], index) {
+			j := index[next]
+			if order[j] == unvisited {
+				visit(j)
+				low[i] = min(low[i], low[j])
+			} else if onStack[j] {
+				low[i] = min(low[i], order[j])
+			}
+		}
+		if low[i] != order[i] {
+			return
+		}
+		component := map[int]int{}
+		for {
+			j := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[j] = false
+			component[pages[j]] = j
+			if j == i {
+				break
+			}
+		}
+		rules := []Rule{}
+		for _, page := range pages {
+			if _, ok := component[page]; !ok {
+				continue
+			}
+			for _, next := range g.successors(page, component) {
+				rules = append(rules, Rule{page, next})
+			}
+		}
+		if len(rules) > 0 {
+			cycles = append(cycles, rules)
+		}
+	}
+	for i := range pages {
+		if order[i] == unvisited {
+			visit(i)
+		}
+	}
+	return cycles
+}
+
+// Violations lists the rules the update breaks, in the order the pages they
+// constrain appear in the update.
+func (g RuleGraph) Violations(u Update) []Rule {
+	rules := []Rule{}
+	for _, page := range u.pages {
+		for _, next := range g.successors(page, u.index) {
+			if r := (Rule{page, next}); !r.Apply(u) {
+				rules = append(rules, r)
+			}
+		}
+	}
+	return rules
+}
+
+func formatPages(pages []int) string {
+	parts := make([]string, len(pages))
+	for i, page := range pages {
+		parts[i] = strconv.Itoa(page)
+	}
+	return strings.Join(parts, ",")
+}
+
+// ExplainUpdates describes each update as valid, as invalid with the rules it
+// breaks and its corrected order, or as impossible to order.
+func ExplainUpdates(inputs []string) []string {
+	instructions := ParseUpdateInstructions(inputs)
+	graph := NewRuleGraph(instructions.rules)
+	lines := make([]string, len(instructions.updates))
+	for i, update := range instructions.updates {
+		line := formatPages(update.pages)
+		violations := graph.Violations(update)
+		if len(violations) == 0 {
+			lines[i] = line + ": valid"
+			continue
+		}
+		broken := make([]string, len(violations))
+		for j, r := range violations {
+			broken[j] = r.String()
+		}
+		line += ": violates " + strings.Join(broken, " ")
+		if order, err := graph.Order(update.pages); err == nil {
+			line += ", corrected to " + formatPages(order)
+		} else {
+			line += ", cannot be corrected: " + err.Error()
+		}
+		lines[i] = line
+	}
+	return lines
+}
@@ -0,0 +1,129 @@
+package day5
+
+import (
+	"errors"
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+var exampleRules = []Rule{
+	{47, 53}, {97, 13}, {97, 61}, {97, 47}, {75, 29}, {61, 13}, {75, 53},
+	{29, 13}, {97, 29}, {53, 29}, {61, 53}, {97, 53}, {61, 29}, {47, 13},
+	{75, 47}, {97, 75}, {47, 61}, {75, 61}, {47, 29}, {75, 13}, {53, 13},
+}
+
+func TestOrder(t *testing.T) {
+	graph := NewRuleGraph(exampleRules)
+	cases := []struct {
+		pages    []int
+		expected []int
+	}{
+		{[]int{75, 47, 61, 53, 29}, []int{75, 47, 61, 53, 29}},
+		{[]int{75, 97, 47, 61, 53}, []int{97, 75, 47, 61, 53}},
+		{[]int{61, 13, 29}, []int{61, 29, 13}},
+		{[]int{97, 13, 75, 29, 47}, []int{97, 75, 47, 29, 13}},
+		{[]int{13, 1, 47}, []int{1, 47, 13}},
+	}
+	for _, c := range cases {
+		result, err := graph.Order(c.pages)
+		if err != nil || !slices.Equal(result, c.expected) {
+			t.Errorf("Order(%v) == %v, %v, expected %v", c.pages, result, err, c.expected)
+		}
+	}
+}
+
+func TestOrderRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(5))
+	for range 100 {
+		// Rules only ever point from a lower rank to a higher one, so they
+		// cannot form a cycle.
+		rank := r.Perm(20)
+		rules := []Rule{}
+		for range 60 {
+			a, b := r.Intn(20), r.Intn(20)
+			if rank[a] < rank[b] {
+				rules = append(rules, Rule{a, b})
+			}
+		}
+		graph := NewRuleGraph(rules)
+		pages := r.Perm(20)[:1+r.Intn(19)]
+		order, err := graph.Order(pages)
+		if err != nil {
+			t.Errorf("Order(%v) with rules %v failed: %v", pages, rules, err)
+			continue
+		}
+		if violations := graph.Violations(NewUpdate(order)); len(violations) != 0 {
+			t.Errorf("Order(%v) with rules %v == %v, which violates %v", pages, rules, order, violations)
+		}
+		if !slices.Equal(slices.Sorted(slices.Values(order)), slices.Sorted(slices.Values(pages))) {
+			t.Errorf("Order(%v) == %v, expected the same pages", pages, order)
+		}
+	}
+}
+
+func TestOrderCycles(t *testing.T) {
+	graph := NewRuleGraph([]Rule{{1, 2}, {2, 3}, {3, 1}, {4, 1}, {5, 5}, {6, 7}, {7, 6}})
+	cases := []struct {
+		pages    []int
+		expected [][]Rule
+	}{
+		{[]int{4, 1, 2, 3}, [][]Rule{{{1, 2}, {2, 3}, {3, 1}}}},
+		{[]int{5, 4}, [][]Rule{{{5, 5}}}},
+		{[]int{7, 1, 6, 3, 2}, [][]Rule{{{7, 6}, {6, 7}}, {{1, 2}, {3, 1}, {2, 3}}}},
+	}
+	for _, c := range cases {
+		result, err := graph.Order(c.pages)
+		var cycleErr *CycleError
+		if !errors.As(err, &cycleErr) {
+			t.Errorf("Order(%v) == %v, %v, expected a CycleError", c.pages, result, err)
+			continue
+		}
+		if !slices.EqualFunc(cycleErr.Cycles, c.expected, slices.Equal) {
+			t.Errorf("Order(%v) reported cycles %v, expected %v", c.pages, cycleErr.Cycles, c.expected)
+		}
+	}
+	if _, err := graph.Order([]int{1, 2, 4}); err != nil {
+		t.Errorf("Order([1 2 4]) failed: %v", err)
+	}
+}
+
+func TestViolations(t *testing.T) {
+	graph := NewRuleGraph(exampleRules)
+	cases := []struct {
+		pages    []int
+		expected []Rule
+	}{
+		{[]int{75, 47, 61, 53, 29}, []Rule{}},
+		{[]int{75, 97, 47, 61, 53}, []Rule{{97, 75}}},
+		{[]int{97, 13, 75, 29, 47}, []Rule{{75, 13}, {29, 13}, {47, 13}, {47, 29}}},
+	}
+	for _, c := range cases {
+		result := graph.Violations(NewUpdate(c.pages))
+		if !slices.Equal(result, c.expected) {
+			t.Errorf("Violations(%v) == %v, expected %v", c.pages, result, c.expected)
+		}
+	}
+}
+
+func TestExplainUpdates(t *testing.T) {
+	inputs := []string{
+		"47|53",
+		"97|75",
+		"75|47",
+		"1|2",
+		"2|1",
+		"",
+		"75,47,53",
+		"47,75,97",
+		"2,1,53",
+	}
+	expected := []string{
+		"75,47,53: valid",
+		"47,75,97: violates 75|47 97|75, corrected to 97,75,47",
+		"2,1,53: violates 1|2, cannot be corrected: cyclic rules: 2|1 1|2",
+	}
+	if result := ExplainUpdates(inputs); !slices.Equal(result, expected) {
+		t.Errorf("ExplainUpdates(%q) == %q, expected %q", inputs, result, expected)
+	}
+}
@@ -2,6 +2,7 @@ package day5
 
 import (
 	"fmt"
+	"log"
 	"strconv"
 	"strings"
 )
@@ -35,16 +36,6 @@ func NewUpdate(pages []int) Update {
 	return Update{pages, index, middle}
 }
 
-func (u *Update) SwapUsing(r Rule) {
-	if first, ok := u.index[r.before]; ok {
-		if second, ok := u.index[r.after]; ok {
-			u.pages[first], u.pages[second] = u.pages[second], u.pages[first]
-			u.index[r.before], u.index[r.after] = u.index[r.after], u.index[r.before]
-			u.middle = u.pages[len(u.pages)/2]
-		}
-	}
-}
-
 type UpdateInstructions struct {
 	rules   []Rule
 	updates []Update
@@ -94,15 +85,9 @@ func ParseUpdateInstructions(inputs []string) UpdateInstructions {
 func SumMiddlePages(inputs []string) int {
 	sum := 0
 	instructions := ParseUpdateInstructions(inputs)
+	graph := NewRuleGraph(instructions.rules)
 	for _, update := range instructions.updates {
-		isValid := true
-		for _, rule := range instructions.rules {
-			if !rule.Apply(update) {
-				isValid = false
-				break
-			}
-		}
-		if isValid {
+		if len(graph.Violations(update)) == 0 {
 			sum += update.middle
 		}
 	}
@@ -112,26 +97,16 @@ func SumMiddlePages(inputs []string) int {
 func SumCorrectedMiddlePages(inputs []string) int {
 	sum := 0
 	instructions := ParseUpdateInstructions(inputs)
+	graph := NewRuleGraph(instructions.rules)
 	for _, update := range instructions.updates {
-		isValid := true
-		for _, rule := range instructions.rules {
-			if !rule.Apply(update) {
-				isValid = false
-				break
-			}
+		if len(graph.Violations(update)) == 0 {
+			continue
 		}
-		if !isValid {
-			for !isValid {
-				isValid = true
-				for _, rule := range instructions.rules {
-					if !rule.Apply(update) {
-						isValid = false
-						update.SwapUsing(rule)
-					}
-				}
-			}
-			sum += update.middle
+		order, err := graph.Order(update.pages)
+		if err != nil {
+			log.Panicf("Cannot correct update %v: %v", update.pages, err)
 		}
+		sum += NewUpdate(order).middle
 	}
 	return sum
 }
@@ -1,6 +1,7 @@
 package day6
 
 import (
+	"aoc2024/cycle"
 	"fmt"
 	"log"
 )
@@ -75,13 +76,11 @@ type Grid struct {
 	nguards, nvisited int
 	squares           []Square
 	guardCoords       Coordinates
-	stateCounts       []int
 }
 
 func NewGrid(nrows, ncols int) Grid {
 	squares := make([]Square, nrows*ncols)
-	guardCoords := make([]int, nrows*ncols*4)
-	return Grid{nrows, ncols, 0, 0, squares, Coordinates{-1, -1}, guardCoords}
+	return Grid{nrows, ncols, 0, 0, squares, Coordinates{-1, -1}}
 }
 
 func (g *Grid) GetValue(c Coordinates) Square {
@@ -92,26 +91,17 @@ func (g *Grid) SetValue(c Coordinates, s Square) {
 	g.squares[c.RowMajorIndex(g.nrows, g.ncols)] = s
 }
 
-func (g *Grid) GetStateCount() int {
-	guard := g.GetValue(g.guardCoords).guard
-	if guard.IsNone() {
-		return -1
-	}
-	row := g.nrows - g.guardCoords.y - 1
-	col := g.guardCoords.x
-	directionIndex := guard.GetValue().directionIndex
-	return g.stateCounts[row*g.ncols*4+col*4+directionIndex]
+type guardState struct {
+	coords         Coordinates
+	directionIndex int
 }
 
-func (g *Grid) IncrementStateCount() {
-	guard := g.GetValue(g.guardCoords).guard
-	if guard.IsNone() {
-		return
+func (g *Grid) getGuardState() Optional[guardState] {
+	if g.nguards == 0 {
+		return None[guardState]()
 	}
-	row := g.nrows - g.guardCoords.y - 1
-	col := g.guardCoords.x
-	directionIndex := guard.GetValue().directionIndex
-	g.stateCounts[row*g.ncols*4+col*4+directionIndex]++
+	guard := g.GetValue(g.guardCoords).guard
+	return Some(guardState{g.guardCoords, guard.GetValue().directionIndex})
 }
 
 func (g *Grid) AddSquare(c Coordinates, s Square) {
@@ -258,14 +248,13 @@ func CountCyclingObstructions(inputs []string) int {
 	log.Printf("Running with %d variations", len(variations))
 	for _, variation := range variations {
 		grid := ParseGrid(variation)
-		grid.IncrementStateCount()
-		for grid.nguards > 0 {
-			grid.Step()
-			grid.IncrementStateCount()
-			if grid.GetStateCount() > 1 {
+		history := cycle.NewHistory[guardState]()
+		for state := grid.getGuardState(); !state.IsNone(); state = grid.getGuardState() {
+			if _, ok := history.Add(state.GetValue()); ok {
 				count++
 				break
 			}
+			grid.Step()
 		}
 	}
 	return count
@@ -2,7 +2,6 @@ package day7
 
 import (
 	"log"
-	"math/big"
 	"slices"
 	"strconv"
 	"strings"
@@ -134,47 +133,11 @@ func ParseEquations(inputs []string) []Equation {
 }
 
 func SumCorrected(inputs []string) int {
-	sum := 0
-	maxSum := big.NewInt(0)
-	equations := ParseEquations(inputs)
-	for _, equation := range equations {
-		maxSum.Add(maxSum, big.NewInt(int64(equation.result)))
-		opsCombos := GenerateOps(len(equation.terms) - 1)
-		for _, ops := range opsCombos {
-			if ok := equation.EvalCheckWith(ops); ok {
-				sum += equation.result
-				break
-			}
-		}
-	}
-	if maxSum.Cmp(big.NewInt(int64(MaxInt))) <= 0 {
-		log.Print("Int is large enough")
-	} else {
-		log.Print("Int may not be large enough")
-	}
-	return sum
+	return sumSolvable(inputs, Operators)
 }
 
 func SumCorrectedWithConcat(inputs []string) int {
-	sum := 0
-	maxSum := big.NewInt(0)
-	equations := ParseEquations(inputs)
-	for _, equation := range equations {
-		maxSum.Add(maxSum, big.NewInt(int64(equation.result)))
-		opsCombos := GenerateOpsWithConcat(len(equation.terms) - 1)
-		for _, ops := range opsCombos {
-			if ok := equation.EvalCheckWith(ops); ok {
-				sum += equation.result
-				break
-			}
-		}
-	}
-	if maxSum.Cmp(big.NewInt(int64(MaxInt))) <= 0 {
-		log.Print("Int is large enough")
-	} else {
-		log.Print("Int may not be large enough")
-	}
-	return sum
+	return sumSolvable(inputs, OperatorsWithConcat)
 }
 
 func GetPossibleResults(termStr string) []int {
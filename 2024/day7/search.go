@@ -0,0 +1,242 @@
+package day7
+
+import (
+	"fmt"
+	"log"
+	"math/big"
+	"slices"
+	"strings"
+)
+
+// ReversibleOp is a BinaryOp that can be undone from the right, which lets a
+// search start at an equation's result and work back towards its first term.
+// Searches with any operator that is only a BinaryOp instead try operators
+// from the first term forwards, which is slower but finds the same solutions.
+type ReversibleOp interface {
+	BinaryOp
+	fmt.Stringer
+	// Undo returns the inclusive range of x with Eval(x, y) == result, or
+	// false if there is none.
+	Undo(result, y int) (lo, hi int, ok bool)
+}
+
+func (AddOp) String() string {
+	return "+"
+}
+
+func (AddOp) Undo(result, y int) (int, int, bool) {
+	return result - y, result - y, true
+}
+
+func (MultiplyOp) String() string {
+	return "*"
+}
+
+func (MultiplyOp) Undo(result, y int) (int, int, bool) {
+	switch {
+	case y == 0 && result == 0:
+		return -MaxInt - 1, MaxInt, true
+	case y == 0 || result%y != 0:
+		return 0, 0, false
+	}
+	return result / y, result / y, true
+}
+
+func (ConcatOp) String() string {
+	return "||"
+}
+
+func (ConcatOp) Undo(result, y int) (int, int, bool) {
+	factor := 1
+	for range CalcDigits(y) {
+		factor *= 10
+	}
+	if (result-y)%factor != 0 {
+		return 0, 0, false
+	}
+	return (result - y) / factor, (result - y) / factor, true
+}
+
+var Operators = []BinaryOp{AddOp{}, MultiplyOp{}}
+
+var OperatorsWithConcat = []BinaryOp{AddOp{}, MultiplyOp{}, ConcatOp{}}
+
+// Expression is a witness for an equation: its terms joined by operators
+// evaluated left to right.
+type Expression struct {
+	Terms []int
+	Ops   []BinaryOp
+}
+
+// opSymbol names an operator by its String method, falling back to its type
+// for plain BinaryOps.
+func opSymbol(op BinaryOp) string {
+	if s, ok := op.(fmt.Stringer); ok {
+		return s.String()
+	}
+	return fmt.Sprintf("%T", op)
+}
+
+func (x Expression) String() string {
+	var b strings.Builder
+	fmt.Fprint(&b, x.Terms[0])
+	for i, op := range x.Ops {
+		fmt.Fprintf(&b, " %s %d", opSymbol(op), x.Terms[i+1])
+	}
+	return b.String()
+}
+
+func (x Expression) Eval() int {
+	result := x.Terms[0]
+	for i, op := range x.Ops {
+		result = op.Eval(result, x.Terms[i+1])
+	}
+	return result
+}
+
+type search struct {
+	terms      []int
+	ops        []BinaryOp
+	reversible []ReversibleOp
+	chosen     []BinaryOp
+	yield      func([]BinaryOp) bool
+}
+
+// combinations returns how many operator sequences join the first n+1 terms,
+// saturating at MaxInt.
+func (s *search) combinations(n int) int {
+	count := 1
+	for range n {
+		if count > MaxInt/len(s.ops) {
+			return MaxInt
+		}
+		count *= len(s.ops)
+	}
+	return count
+}
+
+// backward looks for operators joining terms[:i+1] into target by undoing the
+// operator before terms[i]. It returns false once the search is stopped.
+func (s *search) backward(i, target int) bool {
+	if i == 0 {
+		if s.terms[0] == target {
+			return s.yield(s.chosen)
+		}
+		return true
+	}
+	for _, op := range s.reversible {
+		lo, hi, ok := op.Undo(target, s.terms[i])
+		if !ok {
+			continue
+		}
+		s.chosen[i-1] = op
+		// A wide range of candidates is cheaper to test by evaluating the
+		// remaining prefix forwards than by undoing each candidate.
+		if span := uint(hi - lo); span >= uint(s.combinations(i-1)) {
+			if !s.forward(0, s.terms[0], i-1, lo, hi) {
+				return false
+			}
+			continue
+		}
+		for x := lo; ; x++ {
+			if !s.backward(i-1, x) {
+				return false
+			}
+			if x == hi {
+				break
+			}
+		}
+	}
+	return true
+}
+
+// forward tries every operator sequence joining terms[:end+1], starting from
+// the value of terms[:k+1], and yields those landing within lo..hi.
+func (s *search) forward(k, value, end, lo, hi int) bool {
+	if k == end {
+		if lo <= value && value <= hi {
+			return s.yield(s.chosen)
+		}
+		return true
+	}
+	for _, op := range s.ops {
+		s.chosen[k] = op
+		if !s.forward(k+1, op.Eval(value, s.terms[k+1]), end, lo, hi) {
+			return false
+		}
+	}
+	return true
+}
+
+func (e Equation) search(ops []BinaryOp, yield func([]BinaryOp) bool) {
+	if len(ops) == 0 {
+		log.Panic("Search needs at least one operator")
+	}
+	s := search{e.terms, ops, []ReversibleOp{}, make([]BinaryOp, len(e.terms)-1), yield}
+	for _, op := range ops {
+		if r, ok := op.(ReversibleOp); ok {
+			s.reversible = append(s.reversible, r)
+		}
+	}
+	if len(s.reversible) < len(ops) {
+		s.forward(0, e.terms[0], len(e.terms)-1, e.result, e.result)
+		return
+	}
+	s.backward(len(e.terms)-1, e.result)
+}
+
+// Solve returns an expression of the equation's terms that evaluates to its
+// result, if there is one.
+func (e Equation) Solve(ops []BinaryOp) (Expression, bool) {
+	var solution Expression
+	found := false
+	e.search(ops, func(chosen []BinaryOp) bool {
+		solution = Expression{e.terms, slices.Clone(chosen)}
+		found = true
+		return false
+	})
+	return solution, found
+}
+
+func (e Equation) CountSolutions(ops []BinaryOp) int {
+	count := 0
+	e.search(ops, func([]BinaryOp) bool {
+		count++
+		return true
+	})
+	return count
+}
+
+func sumSolvable(inputs []string, ops []BinaryOp) int {
+	sum := 0
+	maxSum := big.NewInt(0)
+	for _, equation := range ParseEquations(inputs) {
+		maxSum.Add(maxSum, big.NewInt(int64(equation.result)))
+		if _, ok := equation.Solve(ops); ok {
+			sum += equation.result
+		}
+	}
+	if maxSum.Cmp(big.NewInt(int64(MaxInt))) <= 0 {
+		log.Print("Int is large enough")
+	} else {
+		log.Print("Int may not be large enough")
+	}
+	return sum
+}
+
+// ExplainCalibration shows a solution and the number of solutions for each
+// equation, or that it has none.
+func ExplainCalibration(inputs []string, ops []BinaryOp) []string {
+	lines := []string{}
+	for _, equation := range ParseEquations(inputs) {
+		solution, ok := equation.Solve(ops)
+		if !ok {
+			lines = append(lines, fmt.Sprintf("%d: no solution", equation.result))
+			continue
+		}
+		lines = append(lines, fmt.Sprintf(
+			"%d = %v (%d solutions)", equation.result, solution, equation.CountSolutions(ops),
+		))
+	}
+	return lines
+}
@@ -0,0 +1,172 @@
+package day7
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+type subtractOp struct{}
+
+func (subtractOp) Eval(x, y int) int {
+	return x - y
+}
+
+func (subtractOp) String() string {
+	return "-"
+}
+
+func (subtractOp) Undo(result, y int) (int, int, bool) {
+	return result + y, result + y, true
+}
+
+// maxOp has no Undo, so searches using it go forwards.
+type maxOp struct{}
+
+func (maxOp) Eval(x, y int) int {
+	return max(x, y)
+}
+
+func (maxOp) String() string {
+	return "max"
+}
+
+type divideOp struct{}
+
+func (divideOp) Eval(x, y int) int {
+	return x / y
+}
+
+func (divideOp) String() string {
+	return "/"
+}
+
+func (divideOp) Undo(result, y int) (int, int, bool) {
+	if y == 0 {
+		return 0, 0, false
+	}
+	if y < 0 {
+		result, y = -result, -y
+	}
+	switch {
+	case result > 0:
+		return result * y, result*y + y - 1, true
+	case result < 0:
+		return result*y - y + 1, result * y, true
+	}
+	return -y + 1, y - 1, true
+}
+
+func TestSolve(t *testing.T) {
+	cases := []struct {
+		e        Equation
+		ops      []BinaryOp
+		expected string
+		ok       bool
+	}{
+		{NewEquation(190, []int{10, 19}), Operators, "10 * 19", true},
+		{NewEquation(3267, []int{81, 40, 27}), Operators, "81 * 40 + 27", true},
+		{NewEquation(83, []int{17, 5}), Operators, "", false},
+		{NewEquation(156, []int{15, 6}), OperatorsWithConcat, "15 || 6", true},
+		{NewEquation(7290, []int{6, 8, 6, 15}), OperatorsWithConcat, "6 * 8 || 6 * 15", true},
+		{NewEquation(192, []int{17, 8, 14}), OperatorsWithConcat, "17 || 8 + 14", true},
+		{NewEquation(0, []int{5, 0, 7}), Operators, "5 * 0 * 7", true},
+		{NewEquation(4, []int{10, 3, 2}), []BinaryOp{subtractOp{}, divideOp{}}, "", false},
+		{NewEquation(5, []int{10, 3, 2}), []BinaryOp{subtractOp{}, divideOp{}}, "10 - 3 - 2", true},
+		{NewEquation(1, []int{10, 3, 2}), []BinaryOp{subtractOp{}, divideOp{}}, "10 / 3 - 2", true},
+		{NewEquation(-3, []int{3, 9, 2}), []BinaryOp{subtractOp{}, divideOp{}}, "3 - 9 / 2", true},
+		{NewEquation(19, []int{10, 9, 10}), []BinaryOp{maxOp{}, AddOp{}}, "10 + 9 max 10", true},
+		{NewEquation(5, []int{10, 9}), []BinaryOp{maxOp{}, AddOp{}}, "", false},
+	}
+	for _, c := range cases {
+		solution, ok := c.e.Solve(c.ops)
+		if ok != c.ok || ok && solution.String() != c.expected {
+			t.Errorf("%v.Solve(%v) == %q, %v, expected %q, %v", c.e, c.ops, solution, ok, c.expected, c.ok)
+		}
+	}
+}
+
+func TestCountSolutions(t *testing.T) {
+	cases := []struct {
+		e        Equation
+		ops      []BinaryOp
+		expected int
+	}{
+		{NewEquation(3267, []int{81, 40, 27}), Operators, 2},
+		{NewEquation(292, []int{11, 6, 16, 20}), Operators, 1},
+		{NewEquation(4, []int{2, 2}), Operators, 2},
+		{NewEquation(4, []int{2, 2, 1}), Operators, 2},
+		{NewEquation(0, []int{3, 0, 0}), Operators, 3},
+		{NewEquation(0, []int{7, 8, 9, 0}), Operators, 4},
+		{NewEquation(0, []int{7, 8, 9, 0}), OperatorsWithConcat, 9},
+		{NewEquation(10, []int{10, 10, 0}), []BinaryOp{maxOp{}, AddOp{}}, 2},
+	}
+	for _, c := range cases {
+		result := c.e.CountSolutions(c.ops)
+		if result != c.expected {
+			t.Errorf("%v.CountSolutions(%v) == %d, expected %d", c.e, c.ops, result, c.expected)
+		}
+	}
+}
+
+// allOps lists every operator sequence of length n drawn from ops.
+func allOps(ops []BinaryOp, n int) [][]BinaryOp {
+	if n == 0 {
+		return [][]BinaryOp{{}}
+	}
+	combos := [][]BinaryOp{}
+	for _, prefix := range allOps(ops, n-1) {
+		for _, op := range ops {
+			combos = append(combos, append(slices.Clone(prefix), op))
+		}
+	}
+	return combos
+}
+
+func TestCountSolutionsMatchesEnumeration(t *testing.T) {
+	r := rand.New(rand.NewSource(7))
+	opSets := [][]BinaryOp{
+		Operators,
+		OperatorsWithConcat,
+		{AddOp{}, subtractOp{}, MultiplyOp{}, divideOp{}},
+		{AddOp{}, MultiplyOp{}, maxOp{}},
+	}
+	for range 300 {
+		ops := opSets[r.Intn(len(opSets))]
+		terms := make([]int, 2+r.Intn(4))
+		for i := range terms {
+			terms[i] = 1 + r.Intn(9)
+		}
+		combos := allOps(ops, len(terms)-1)
+		target := Expression{terms, combos[r.Intn(len(combos))]}.Eval()
+		if r.Intn(4) == 0 {
+			target += r.Intn(5) - 2
+		}
+		expected := 0
+		for _, combo := range combos {
+			if (Expression{terms, combo}).Eval() == target {
+				expected++
+			}
+		}
+		e := NewEquation(target, terms)
+		if result := e.CountSolutions(ops); result != expected {
+			t.Errorf("%v.CountSolutions(%v) == %d, expected %d", e, ops, result, expected)
+		}
+		solution, ok := e.Solve(ops)
+		if ok != (expected > 0) || ok && solution.Eval() != target {
+			t.Errorf("%v.Solve(%v) == %v, %v, expected a solution: %v", e, ops, solution, ok, expected > 0)
+		}
+	}
+}
+
+func TestExplainCalibration(t *testing.T) {
+	inputs := []string{"190: 10 19", "83: 17 5", "3267: 81 40 27"}
+	expected := []string{
+		"190 = 10 * 19 (1 solutions)",
+		"83: no solution",
+		"3267 = 81 * 40 + 27 (2 solutions)",
+	}
+	if result := ExplainCalibration(inputs, Operators); !slices.Equal(result, expected) {
+		t.Errorf("ExplainCalibration(%q, %v) == %q, expected %q", inputs, Operators, result, expected)
+	}
+}
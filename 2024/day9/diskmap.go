@@ -58,12 +58,15 @@ func (m *ExpandedDiskMap) Extend(diskMapIndex int, diskMapValue rune) {
 		files = []File{{fileStart, fileEnd}}
 		freeSpaces = []FreeSpace{}
 		emptyIndices = []int{}
-		lastFileIndex = fileEnd
+		lastFileIndex = m.lastFileIndex
+		if nBlocks > 0 {
+			lastFileIndex = fileEnd
+		}
 	} else {
 		empty = true
 		fileId = -1
 		files = []File{}
-		freeStart := m.lastFileIndex + 1
+		freeStart := len(m.blocks)
 		freeEnd := freeStart + nBlocks - 1
 		freeSpaces = []FreeSpace{{freeStart, freeEnd}}
 		emptyIndices = make([]int, nBlocks)
@@ -82,7 +85,7 @@ func (m *ExpandedDiskMap) Extend(diskMapIndex int, diskMapValue rune) {
 }
 
 func (m *ExpandedDiskMap) Swap() bool {
-	if m.lastFileIndex < m.emptyIndices[0] {
+	if len(m.emptyIndices) == 0 || m.lastFileIndex < m.emptyIndices[0] {
 		return false
 	}
 	m.blocks[m.emptyIndices[0]], m.blocks[m.lastFileIndex] = m.blocks[m.lastFileIndex], m.blocks[m.emptyIndices[0]]
@@ -179,8 +182,6 @@ func CalcChecksum(inputs []string) int {
 func CalcChecksumFileSwap(inputs []string) int {
 	diskMap := inputs[0]
 	expandedDiskMap := ExpandDiskMap(diskMap)
-	for i := len(expandedDiskMap.files) - 1; i >= 0; i-- {
-		expandedDiskMap.SwapFile(i)
-	}
+	expandedDiskMap.CompactFiles()
 	return expandedDiskMap.CalcChecksum()
 }
@@ -1,6 +1,11 @@
 package day9
 
-import "testing"
+import (
+	"math/rand"
+	"slices"
+	"strings"
+	"testing"
+)
 
 func TestCalcChecksum(t *testing.T) {
 	cases := []struct {
@@ -9,6 +14,13 @@ func TestCalcChecksum(t *testing.T) {
 	}{
 		{[]string{"12345"}, 60},
 		{[]string{"2333133121414131402"}, 1928},
+		{[]string{"9"}, 0},
+		{[]string{"90"}, 0},
+		{[]string{"1"}, 0},
+		{[]string{"010"}, 0},
+		{[]string{"0112"}, 0},
+		{[]string{"0121"}, 1},
+		{[]string{"12031"}, 2},
 	}
 	for _, c := range cases {
 		result := CalcChecksum(c.inputs)
@@ -27,6 +39,12 @@ func TestCalcChecksumFileSwap(t *testing.T) {
 		{[]string{"122"}, 3},
 		{[]string{"14222"}, 13},
 		{[]string{"2333133121414131402"}, 2858},
+		// An empty file splits the free space the last file sees, but not
+		// the space seen by the files before it.
+		{[]string{"76079"}, 432},
+		{[]string{"51074"}, 60},
+		{[]string{"1101542"}, 101},
+		{[]string{"1101542101"}, 59},
 	}
 	for _, c := range cases {
 		result := CalcChecksumFileSwap(c.inputs)
@@ -74,6 +92,31 @@ func TestExpandDiskMap(t *testing.T) {
 				lastFileIndex: 14,
 			},
 		},
+		{
+			"12031",
+			ExpandedDiskMap{
+				blocks: []Block{
+					{empty: false, fileId: 0},
+					{empty: true, fileId: -1},
+					{empty: true, fileId: -1},
+					{empty: true, fileId: -1},
+					{empty: true, fileId: -1},
+					{empty: true, fileId: -1},
+					{empty: false, fileId: 2},
+				},
+				files: []File{
+					{start: 0, end: 0},
+					{start: 3, end: 2},
+					{start: 6, end: 6},
+				},
+				freeSpaces: []FreeSpace{
+					{start: 1, end: 2},
+					{start: 3, end: 5},
+				},
+				emptyIndices:  []int{1, 2, 3, 4, 5},
+				lastFileIndex: 6,
+			},
+		},
 	}
 	for _, c := range cases {
 		result := ExpandDiskMap(c.input)
@@ -121,3 +164,132 @@ func TestExpandDiskMap(t *testing.T) {
 		}
 	}
 }
+
+func randomDiskMap(r *rand.Rand, nDigits int) string {
+	digits := make([]byte, nDigits)
+	for i := range digits {
+		digits[i] = byte('0' + r.Intn(10))
+	}
+	return string(digits)
+}
+
+// nonEmptyDiskMap is randomDiskMap with every file at least one block long.
+func nonEmptyDiskMap(r *rand.Rand, nDigits int) string {
+	digits := []byte(randomDiskMap(r, nDigits))
+	for i := 0; i < len(digits); i += 2 {
+		digits[i] = byte('1' + r.Intn(9))
+	}
+	return string(digits)
+}
+
+// previousFileSwap is CalcChecksumFileSwap as it was before CompactFiles,
+// rebuilding the block array after every file.
+func previousFileSwap(diskMap string) int {
+	m := ExpandDiskMap(diskMap)
+	for i := len(m.files) - 1; i >= 0; i-- {
+		m.SwapFile(i)
+	}
+	return m.CalcChecksum()
+}
+
+// firstFitFiles is a simple first-fit reference that keeps, for every file
+// size, the position of the leftmost free space that may still fit it. Like
+// the previous implementation it moves the last file with SwapFile first.
+func firstFitFiles(diskMap string) int {
+	m := ExpandDiskMap(diskMap)
+	m.SwapFile(len(m.files) - 1)
+	spaces := slices.Clone(m.freeSpaces)
+	next := make([]int, 10)
+	sum := 0
+	for id := len(m.files) - 1; id >= 0; id-- {
+		f := m.files[id]
+		size := f.end - f.start + 1
+		start := f.start
+		if size > 0 && id < len(m.files)-1 {
+			i := next[size]
+			for i < len(spaces) && spaces[i].end-spaces[i].start+1 < size {
+				i++
+			}
+			next[size] = i
+			if i < len(spaces) && spaces[i].start < f.start {
+				start = spaces[i].start
+				spaces[i].start += size
+			}
+		}
+		for b := start; b < start+size; b++ {
+			sum += b * id
+		}
+	}
+	return sum
+}
+
+func TestCompactFilesMatchesSwapFile(t *testing.T) {
+	r := rand.New(rand.NewSource(9))
+	for range 20 {
+		diskMap := randomDiskMap(r, 1+r.Intn(2000))
+		if r.Intn(2) == 0 {
+			diskMap = nonEmptyDiskMap(r, 1+r.Intn(2000))
+		}
+		expected := previousFileSwap(diskMap)
+		result := CalcChecksumFileSwap([]string{diskMap})
+		if result != expected {
+			t.Fatalf("CalcChecksumFileSwap(%q) == %d, expected %d", diskMap, result, expected)
+		}
+	}
+	for range 5000 {
+		diskMap := randomDiskMap(r, 1+r.Intn(12))
+		expected := previousFileSwap(diskMap)
+		if result := CalcChecksumFileSwap([]string{diskMap}); result != expected {
+			t.Fatalf("CalcChecksumFileSwap(%q) == %d, expected %d", diskMap, result, expected)
+		}
+		if result := firstFitFiles(diskMap); result != expected {
+			t.Fatalf("firstFitFiles(%q) == %d, expected %d", diskMap, result, expected)
+		}
+	}
+}
+
+// largeDiskMaps returns a random disk map and two that alternate tiny and
+// huge files or spaces, each of n digits.
+func largeDiskMaps(seed int64, n int) []string {
+	r := rand.New(rand.NewSource(seed))
+	return []string{
+		randomDiskMap(r, n),
+		strings.Repeat("19", n/2),
+		strings.Repeat("91", n/2),
+	}
+}
+
+// The previous implementation rebuilds every block for each file, which
+// takes hours on a million digits, so it checks the same kinds of disk map
+// at a few thousand digits and firstFitFiles stands in for it at full size.
+func TestCompactFilesMillionDigits(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping million digit disk map in short mode")
+	}
+	for _, diskMap := range largeDiskMaps(1_000_000, 4_000) {
+		result := CalcChecksumFileSwap([]string{diskMap})
+		if expected := previousFileSwap(diskMap); result != expected {
+			t.Errorf("CalcChecksumFileSwap(%.20q...) == %d, expected %d", diskMap, result, expected)
+		}
+	}
+	for _, diskMap := range largeDiskMaps(1_000_000, 1_000_000) {
+		result := CalcChecksumFileSwap([]string{diskMap})
+		if expected := firstFitFiles(diskMap); result != expected {
+			t.Errorf("CalcChecksumFileSwap(%.20q...) == %d, expected %d", diskMap, result, expected)
+		}
+	}
+}
+
+func BenchmarkCompactFiles(b *testing.B) {
+	diskMap := randomDiskMap(rand.New(rand.NewSource(1)), 20_000)
+	for range b.N {
+		ExpandDiskMap(diskMap).CompactFiles()
+	}
+}
+
+func BenchmarkSwapFile(b *testing.B) {
+	diskMap := randomDiskMap(rand.New(rand.NewSource(1)), 20_000)
+	for range b.N {
+		previousFileSwap(diskMap)
+	}
+}
@@ -0,0 +1,86 @@
+package day9
+
+import "container/heap"
+
+type startHeap []int
+
+func (h startHeap) Len() int           { return len(h) }
+func (h startHeap) Less(i, j int) bool { return h[i] < h[j] }
+func (h startHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *startHeap) Push(x any)        { *h = append(*h, x.(int)) }
+
+func (h *startHeap) Pop() any {
+	old := *h
+	n := len(old)
+	x := old[n-1]
+	*h = old[:n-1]
+	return x
+}
+
+// freeSpaceIndex keeps the start of every free space in a min-heap per
+// size, so the leftmost space that fits a file is found by checking the
+// top of each heap at least as large as the file.
+type freeSpaceIndex struct {
+	bySize []startHeap
+}
+
+func newFreeSpaceIndex(freeSpaces []FreeSpace) *freeSpaceIndex {
+	maxSize := 0
+	for _, f := range freeSpaces {
+		maxSize = max(maxSize, f.end-f.start+1)
+	}
+	index := &freeSpaceIndex{make([]startHeap, maxSize+1)}
+	for _, f := range freeSpaces {
+		index.add(f.start, f.end-f.start+1)
+	}
+	return index
+}
+
+func (x *freeSpaceIndex) add(start, size int) {
+	if size > 0 {
+		heap.Push(&x.bySize[size], start)
+	}
+}
+
+// take removes the leftmost free space of at least size blocks starting
+// before limit, returning its start and putting any remainder back.
+func (x *freeSpaceIndex) take(size, limit int) (int, bool) {
+	best, bestSize := limit, -1
+	for s := size; s < len(x.bySize); s++ {
+		if len(x.bySize[s]) > 0 && x.bySize[s][0] < best {
+			best, bestSize = x.bySize[s][0], s
+		}
+	}
+	if bestSize < 0 {
+		return 0, false
+	}
+	heap.Pop(&x.bySize[bestSize])
+	x.add(best+size, bestSize-size)
+	return best, true
+}
+
+// CompactFiles moves each file, highest id first, into the leftmost free
+// space before it that can hold the whole file. Space freed by a move is
+// never reused, since it lies to the right of every file still to move.
+// The last file goes through SwapFile, which uses the free spaces recorded
+// by Extend, where an empty file splits the space around it in two. Every
+// later file sees those spaces merged, as when SwapFile rebuilt them.
+func (m *ExpandedDiskMap) CompactFiles() {
+	if len(m.files) == 0 {
+		return
+	}
+	m.SwapFile(len(m.files) - 1)
+	index := newFreeSpaceIndex(m.freeSpaces)
+	for id := len(m.files) - 2; id >= 0; id-- {
+		file := m.files[id]
+		size := file.end - file.start + 1
+		if size == 0 {
+			continue
+		}
+		if start, ok := index.take(size, file.start); ok {
+			m.files[id] = File{start: start, end: start + size - 1}
+		}
+	}
+	m.UpdateBlocks()
+	m.UpdateFreeSpaces()
+}
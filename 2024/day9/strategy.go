@@ -0,0 +1,234 @@
+package day9
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Strategy compacts a disk map in place and returns the number of moves
+// it made.
+type Strategy interface {
+	Compact(m *ExpandedDiskMap) int
+}
+
+type blockStrategy struct{}
+
+func (blockStrategy) Compact(m *ExpandedDiskMap) int {
+	moves := 0
+	for m.Swap() {
+		moves++
+	}
+	return moves
+}
+
+type firstFitStrategy struct{}
+
+func (firstFitStrategy) Compact(m *ExpandedDiskMap) int {
+	before := slices.Clone(m.files)
+	m.CompactFiles()
+	moves := 0
+	for i, f := range m.files {
+		if f != before[i] {
+			moves++
+		}
+	}
+	return moves
+}
+
+// sortedGaps holds free space starts sorted per size, for strategies that
+// need more than the leftmost space of each size.
+type sortedGaps [][]int
+
+func newSortedGaps(freeSpaces []FreeSpace) sortedGaps {
+	maxSize := 0
+	for _, f := range freeSpaces {
+		maxSize = max(maxSize, f.end-f.start+1)
+	}
+	gaps := make(sortedGaps, maxSize+1)
+	for _, f := range freeSpaces {
+		gaps.add(f.start, f.end-f.start+1)
+	}
+	return gaps
+}
+
+func (g sortedGaps) add(start, size int) {
+	if size > 0 {
+		i, _ := slices.BinarySearch(g[size], start)
+		g[size] = slices.Insert(g[size], i, start)
+	}
+}
+
+func (g sortedGaps) remove(start, size int) {
+	i, _ := slices.BinarySearch(g[size], start)
+	g[size] = slices.Delete(g[size], i, i+1)
+}
+
+// pickFunc chooses a free space of at least size blocks starting before
+// limit, returning its start and size.
+type pickFunc func(g sortedGaps, size, limit int) (int, int, bool)
+
+func pickBest(g sortedGaps, size, limit int) (int, int, bool) {
+	for s := size; s < len(g); s++ {
+		if len(g[s]) > 0 && g[s][0] < limit {
+			return g[s][0], s, true
+		}
+	}
+	return 0, 0, false
+}
+
+func pickWorst(g sortedGaps, size, limit int) (int, int, bool) {
+	for s := len(g) - 1; s >= size; s-- {
+		if len(g[s]) > 0 && g[s][0] < limit {
+			return g[s][0], s, true
+		}
+	}
+	return 0, 0, false
+}
+
+func pickRightmost(g sortedGaps, size, limit int) (int, int, bool) {
+	best, bestSize := -1, 0
+	for s := size; s < len(g); s++ {
+		i, _ := slices.BinarySearch(g[s], limit)
+		if i > 0 && g[s][i-1] > best {
+			best, bestSize = g[s][i-1], s
+		}
+	}
+	return best, bestSize, best >= 0
+}
+
+// fitStrategy moves whole files, highest id first, into the free space
+// chosen by pick.
+type fitStrategy struct {
+	pick pickFunc
+}
+
+func (s fitStrategy) Compact(m *ExpandedDiskMap) int {
+	m.UpdateFreeSpaces()
+	gaps := newSortedGaps(m.freeSpaces)
+	moves := 0
+	for id := len(m.files) - 1; id >= 0; id-- {
+		file := m.files[id]
+		size := file.end - file.start + 1
+		if size == 0 {
+			continue
+		}
+		if start, gapSize, ok := s.pick(gaps, size, file.start); ok {
+			gaps.remove(start, gapSize)
+			gaps.add(start+size, gapSize-size)
+			m.files[id] = File{start: start, end: start + size - 1}
+			moves++
+		}
+	}
+	m.UpdateBlocks()
+	m.UpdateFreeSpaces()
+	return moves
+}
+
+// defragStrategy makes every file contiguous and packs the files to the
+// start of the disk, keeping their order.
+type defragStrategy struct{}
+
+func (defragStrategy) Compact(m *ExpandedDiskMap) int {
+	order := []int{}
+	seen := make([]bool, len(m.files))
+	sizes := make([]int, len(m.files))
+	for _, b := range m.blocks {
+		if b.empty {
+			continue
+		}
+		sizes[b.fileId]++
+		if !seen[b.fileId] {
+			seen[b.fileId] = true
+			order = append(order, b.fileId)
+		}
+	}
+	fragments := m.fragments()
+	moves := 0
+	next := 0
+	for _, id := range order {
+		f := File{start: next, end: next + sizes[id] - 1}
+		if f != m.files[id] || fragments[id] > 1 {
+			moves++
+		}
+		m.files[id] = f
+		next += sizes[id]
+	}
+	m.UpdateBlocks()
+	m.UpdateFreeSpaces()
+	return moves
+}
+
+var strategies = map[string]Strategy{
+	"blocks":   blockStrategy{},
+	"firstfit": firstFitStrategy{},
+	"bestfit":  fitStrategy{pickBest},
+	"worstfit": fitStrategy{pickWorst},
+	"rightfit": fitStrategy{pickRightmost},
+	"defrag":   defragStrategy{},
+}
+
+func StrategyNames() []string {
+	names := []string{}
+	for name := range strategies {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// fragments counts the separate runs of blocks each file is stored in.
+func (m *ExpandedDiskMap) fragments() []int {
+	counts := make([]int, len(m.files))
+	for i, b := range m.blocks {
+		if !b.empty && (i == 0 || m.blocks[i-1] != b) {
+			counts[b.fileId]++
+		}
+	}
+	return counts
+}
+
+// holes counts the free spaces lying between used blocks.
+func (m *ExpandedDiskMap) holes() int {
+	holes := 0
+	used := false
+	for i, b := range m.blocks {
+		if !b.empty {
+			if used && m.blocks[i-1].empty {
+				holes++
+			}
+			used = true
+		}
+	}
+	return holes
+}
+
+type Report struct {
+	Strategy        string
+	Moves           int
+	FragmentedFiles int
+	Holes           int
+	Checksum        int
+}
+
+func (r Report) String() string {
+	return fmt.Sprintf("%s: %d moves, %d fragmented files, %d holes, checksum %d",
+		r.Strategy, r.Moves, r.FragmentedFiles, r.Holes, r.Checksum)
+}
+
+func CompactWith(inputs []string, name string) (Report, error) {
+	strategy, ok := strategies[name]
+	if !ok {
+		return Report{}, fmt.Errorf("unknown strategy %q, expected one of %s", name, strings.Join(StrategyNames(), ", "))
+	}
+	m := ExpandDiskMap(inputs[0])
+	report := Report{Strategy: name, Moves: strategy.Compact(m)}
+	for _, n := range m.fragments() {
+		if n > 1 {
+			report.FragmentedFiles++
+		}
+	}
+	report.Holes = m.holes()
+	report.Checksum = m.CalcChecksum()
+	return report, nil
+}
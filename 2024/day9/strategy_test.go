@@ -0,0 +1,72 @@
+package day9
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+	"testing"
+)
+
+func TestCompactWith(t *testing.T) {
+	cases := []struct {
+		input    string
+		strategy string
+		expected Report
+	}{
+		{"12345", "blocks", Report{"blocks", 5, 1, 0, 60}},
+		{"2333133121414131402", "blocks", Report{"blocks", 12, 2, 0, 1928}},
+		{"2333133121414131402", "firstfit", Report{"firstfit", 4, 0, 5, 2858}},
+		{"12345", "firstfit", Report{"firstfit", 0, 0, 2, 132}},
+		{"1412132", "firstfit", Report{"firstfit", 3, 0, 0, 19}},
+		{"1412132", "bestfit", Report{"bestfit", 3, 0, 1, 43}},
+		{"1412132", "worstfit", Report{"worstfit", 3, 0, 0, 19}},
+		{"1213142", "worstfit", Report{"worstfit", 3, 0, 2, 60}},
+		{"1412132", "rightfit", Report{"rightfit", 3, 0, 2, 70}},
+		{"12345", "defrag", Report{"defrag", 2, 0, 0, 66}},
+		{"9", "blocks", Report{"blocks", 0, 0, 0, 0}},
+		{"90", "blocks", Report{"blocks", 0, 0, 0, 0}},
+		{"1", "blocks", Report{"blocks", 0, 0, 0, 0}},
+		{"909", "blocks", Report{"blocks", 0, 0, 0, 117}},
+	}
+	for _, c := range cases {
+		result, err := CompactWith([]string{c.input}, c.strategy)
+		if err != nil || result != c.expected {
+			t.Errorf("CompactWith(%q, %q) == %v, %v, expected %v", c.input, c.strategy, result, err, c.expected)
+		}
+	}
+	if _, err := CompactWith([]string{"12345"}, "nextfit"); err == nil {
+		t.Errorf("CompactWith accepted an unknown strategy")
+	}
+}
+
+func TestCompactWithShortDiskMaps(t *testing.T) {
+	for n := range 1000 {
+		for _, diskMap := range []string{strconv.Itoa(n), fmt.Sprintf("%03d", n)} {
+			for _, name := range StrategyNames() {
+				if _, err := CompactWith([]string{diskMap}, name); err != nil {
+					t.Errorf("CompactWith(%q, %q) failed: %v", diskMap, name, err)
+				}
+			}
+		}
+	}
+}
+
+func TestFirstFitStrategyMatchesCompactFiles(t *testing.T) {
+	r := rand.New(rand.NewSource(42))
+	for range 50 {
+		diskMap := randomDiskMap(r, 1+r.Intn(500))
+		report, _ := CompactWith([]string{diskMap}, "firstfit")
+		if expected := CalcChecksumFileSwap([]string{diskMap}); report.Checksum != expected {
+			t.Errorf("firstfit checksum of %q == %d, expected %d", diskMap, report.Checksum, expected)
+		}
+		for _, name := range StrategyNames() {
+			report, _ := CompactWith([]string{diskMap}, name)
+			if name != "blocks" && report.FragmentedFiles != 0 {
+				t.Errorf("%s left %d fragmented files in %q", name, report.FragmentedFiles, diskMap)
+			}
+			if name == "defrag" && report.Holes != 0 {
+				t.Errorf("defrag left %d holes in %q", report.Holes, diskMap)
+			}
+		}
+	}
+}
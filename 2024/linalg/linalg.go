@@ -0,0 +1,189 @@
+package linalg
+
+import (
+	"math/big"
+	"strings"
+)
+
+type Matrix struct {
+	rows, cols int
+	values     []*big.Rat
+}
+
+func NewMatrix(rows, cols int) *Matrix {
+	m := &Matrix{rows, cols, make([]*big.Rat, rows*cols)}
+	for i := range m.values {
+		m.values[i] = new(big.Rat)
+	}
+	return m
+}
+
+func FromInts(values [][]int) *Matrix {
+	rows, cols := len(values), 0
+	if rows > 0 {
+		cols = len(values[0])
+	}
+	m := NewMatrix(rows, cols)
+	for i, row := range values {
+		if len(row) != cols {
+			panic("linalg: rows of unequal length")
+		}
+		for j, v := range row {
+			m.At(i, j).SetInt64(int64(v))
+		}
+	}
+	return m
+}
+
+func (m *Matrix) Rows() int {
+	return m.rows
+}
+
+func (m *Matrix) Cols() int {
+	return m.cols
+}
+
+func (m *Matrix) At(i, j int) *big.Rat {
+	return m.values[i*m.cols+j]
+}
+
+func (m *Matrix) Set(i, j int, v *big.Rat) {
+	m.At(i, j).Set(v)
+}
+
+func (m *Matrix) Clone() *Matrix {
+	c := NewMatrix(m.rows, m.cols)
+	for i, v := range m.values {
+		c.values[i].Set(v)
+	}
+	return c
+}
+
+func (m *Matrix) String() string {
+	rows := make([]string, m.rows)
+	for i := range m.rows {
+		parts := make([]string, m.cols)
+		for j := range m.cols {
+			parts[j] = m.At(i, j).RatString()
+		}
+		rows[i] = "[" + strings.Join(parts, " ") + "]"
+	}
+	return "[" + strings.Join(rows, " ") + "]"
+}
+
+func (m *Matrix) swapRows(i, k int) {
+	for j := range m.cols {
+		m.values[i*m.cols+j], m.values[k*m.cols+j] = m.values[k*m.cols+j], m.values[i*m.cols+j]
+	}
+}
+
+// Reduce transforms m in place to reduced row echelon form using exact
+// Gaussian elimination and returns the pivot column of each non-zero row.
+func (m *Matrix) Reduce() []int {
+	pivots := []int{}
+	tmp := new(big.Rat)
+	row := 0
+	for col := 0; col < m.cols && row < m.rows; col++ {
+		pivot := -1
+		for i := row; i < m.rows; i++ {
+			if m.At(i, col).Sign() != 0 {
+				pivot = i
+				break
+			}
+		}
+		if pivot < 0 {
+			continue
+		}
+		m.swapRows(row, pivot)
+
+		inv := new(big.Rat).Inv(m.At(row, col))
+		for j := col; j < m.cols; j++ {
+			m.At(row, j).Mul(m.At(row, j), inv)
+		}
+		for i := range m.rows {
+			if i == row || m.At(i, col).Sign() == 0 {
+				continue
+			}
+			factor := new(big.Rat).Set(m.At(i, col))
+			for j := col; j < m.cols; j++ {
+				tmp.Mul(factor, m.At(row, j))
+				m.At(i, j).Sub(m.At(i, j), tmp)
+			}
+		}
+		pivots = append(pivots, col)
+		row++
+	}
+	return pivots
+}
+
+func (m *Matrix) Rank() int {
+	return len(m.Clone().Reduce())
+}
+
+type Solution struct {
+	Rank       int
+	Consistent bool
+	Unique     bool
+	Values     []*big.Rat
+}
+
+// Solve solves a x = b. When the system is consistent, Values holds a
+// particular solution with every free variable set to zero.
+func Solve(a *Matrix, b []*big.Rat) Solution {
+	if len(b) != a.rows {
+		panic("linalg: right hand side does not match matrix rows")
+	}
+	aug := NewMatrix(a.rows, a.cols+1)
+	for i := range a.rows {
+		for j := range a.cols {
+			aug.Set(i, j, a.At(i, j))
+		}
+		aug.Set(i, a.cols, b[i])
+	}
+	pivots := aug.Reduce()
+
+	solution := Solution{Consistent: true}
+	for _, col := range pivots {
+		if col == a.cols {
+			solution.Consistent = false
+		} else {
+			solution.Rank++
+		}
+	}
+	if !solution.Consistent {
+		return solution
+	}
+	solution.Unique = solution.Rank == a.cols
+	solution.Values = make([]*big.Rat, a.cols)
+	for j := range solution.Values {
+		solution.Values[j] = new(big.Rat)
+	}
+	for i, col := range pivots {
+		solution.Values[col].Set(aug.At(i, a.cols))
+	}
+	return solution
+}
+
+func SolveInts(a [][]int, b []int) Solution {
+	rhs := make([]*big.Rat, len(b))
+	for i, v := range b {
+		rhs[i] = new(big.Rat).SetInt64(int64(v))
+	}
+	return Solve(FromInts(a), rhs)
+}
+
+// Integers returns the solution values as ints when every value is an
+// integer that fits in an int.
+func (s Solution) Integers() ([]int, bool) {
+	if !s.Consistent {
+		return nil, false
+	}
+	ints := make([]int, len(s.Values))
+	for i, v := range s.Values {
+		if !v.IsInt() || !v.Num().IsInt64() {
+			return nil, false
+		}
+		ints[i] = int(v.Num().Int64())
+	}
+	return ints, true
+}
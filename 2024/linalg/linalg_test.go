@@ -0,0 +1,138 @@
+package linalg
+
+import (
+	"math/big"
+	"slices"
+	"testing"
+)
+
+func TestReduce(t *testing.T) {
+	cases := []struct {
+		values   [][]int
+		expected string
+		pivots   []int
+	}{
+		{
+			[][]int{{2, 4}, {1, 3}},
+			"[[1 0] [0 1]]",
+			[]int{0, 1},
+		},
+		{
+			[][]int{{1, 2, 3}, {2, 4, 6}},
+			"[[1 2 3] [0 0 0]]",
+			[]int{0},
+		},
+		{
+			[][]int{{0, 2, 1}, {0, 4, 3}},
+			"[[0 1 0] [0 0 1]]",
+			[]int{1, 2},
+		},
+		{
+			[][]int{{3, 1}, {1, 2}, {2, 2}},
+			"[[1 0] [0 1] [0 0]]",
+			[]int{0, 1},
+		},
+	}
+	for _, c := range cases {
+		m := FromInts(c.values)
+		pivots := m.Reduce()
+		if m.String() != c.expected || !slices.Equal(pivots, c.pivots) {
+			t.Errorf("Reduce(%v) == %s, %v, expected %s, %v", c.values, m, pivots, c.expected, c.pivots)
+		}
+	}
+}
+
+func TestRank(t *testing.T) {
+	cases := []struct {
+		values   [][]int
+		expected int
+	}{
+		{[][]int{{94, 22}, {34, 67}}, 2},
+		{[][]int{{2, 1}, {4, 2}}, 1},
+		{[][]int{{0, 0}, {0, 0}}, 0},
+		{[][]int{{1, 2, 3}, {4, 5, 6}, {7, 8, 9}}, 2},
+	}
+	for _, c := range cases {
+		m := FromInts(c.values)
+		result := m.Rank()
+		if result != c.expected {
+			t.Errorf("Rank(%v) == %d, expected %d", c.values, result, c.expected)
+		}
+		if m.String() != FromInts(c.values).String() {
+			t.Errorf("Rank(%v) modified the matrix to %s", c.values, m)
+		}
+	}
+}
+
+func TestSolveInts(t *testing.T) {
+	cases := []struct {
+		a          [][]int
+		b          []int
+		rank       int
+		consistent bool
+		unique     bool
+		values     []string
+	}{
+		{
+			[][]int{{94, 22}, {34, 67}},
+			[]int{8400, 5400},
+			2, true, true,
+			[]string{"80", "40"},
+		},
+		{
+			[][]int{{26, 67}, {66, 21}},
+			[]int{12748, 12176},
+			2, true, true,
+			[]string{"137021/969", "131198/969"},
+		},
+		{
+			[][]int{{2, 1}, {2, 1}},
+			[]int{10, 10},
+			1, true, false,
+			[]string{"5", "0"},
+		},
+		{
+			[][]int{{1, 2}, {1, 2}},
+			[]int{3, 4},
+			1, false, false,
+			nil,
+		},
+		{
+			[][]int{{0, 0}, {0, 0}},
+			[]int{0, 0},
+			0, true, false,
+			[]string{"0", "0"},
+		},
+	}
+	for _, c := range cases {
+		s := SolveInts(c.a, c.b)
+		values := []string(nil)
+		for _, v := range s.Values {
+			values = append(values, v.RatString())
+		}
+		if s.Rank != c.rank || s.Consistent != c.consistent || s.Unique != c.unique || !slices.Equal(values, c.values) {
+			t.Errorf(
+				"SolveInts(%v, %v) == {%d %t %t %v}, expected {%d %t %t %v}",
+				c.a, c.b, s.Rank, s.Consistent, s.Unique, values, c.rank, c.consistent, c.unique, c.values,
+			)
+		}
+	}
+}
+
+func TestIntegers(t *testing.T) {
+	cases := []struct {
+		values   []*big.Rat
+		expected []int
+		ok       bool
+	}{
+		{[]*big.Rat{big.NewRat(80, 1), big.NewRat(-40, 1)}, []int{80, -40}, true},
+		{[]*big.Rat{big.NewRat(6, 3), big.NewRat(1, 2)}, nil, false},
+		{[]*big.Rat{}, []int{}, true},
+	}
+	for _, c := range cases {
+		result, ok := Solution{Consistent: true, Values: c.values}.Integers()
+		if ok != c.ok || !slices.Equal(result, c.expected) {
+			t.Errorf("Integers(%v) == %v, %t, expected %v, %t", c.values, result, ok, c.expected, c.ok)
+		}
+	}
+}
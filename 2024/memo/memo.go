@@ -0,0 +1,92 @@
+package memo
+
+import (
+	"container/list"
+	"fmt"
+)
+
+type Pair[A, B comparable] struct {
+	First  A
+	Second B
+}
+
+type Triple[A, B, C comparable] struct {
+	First  A
+	Second B
+	Third  C
+}
+
+type Stats struct {
+	Hits, Misses, Evictions, Size int
+}
+
+func (s Stats) String() string {
+	rate := 0.0
+	if calls := s.Hits + s.Misses; calls > 0 {
+		rate = float64(s.Hits) / float64(calls)
+	}
+	return fmt.Sprintf(
+		"hits=%d misses=%d hit-rate=%.3f evictions=%d size=%d",
+		s.Hits, s.Misses, rate, s.Evictions, s.Size,
+	)
+}
+
+type entry[K comparable, V any] struct {
+	key   K
+	value V
+}
+
+// Func memoizes a recursive function. The wrapped function receives the
+// memoized function itself so that recursive calls go through the cache.
+type Func[K comparable, V any] struct {
+	fn       func(func(K) V, K) V
+	capacity int
+	cache    map[K]*list.Element
+	order    *list.List
+	stats    Stats
+}
+
+func New[K comparable, V any](fn func(func(K) V, K) V) *Func[K, V] {
+	return NewLRU(0, fn)
+}
+
+// NewLRU bounds the cache to capacity entries, evicting the least recently
+// used entry when full. A capacity of zero or less leaves it unbounded.
+func NewLRU[K comparable, V any](capacity int, fn func(func(K) V, K) V) *Func[K, V] {
+	return &Func[K, V]{fn, capacity, map[K]*list.Element{}, list.New(), Stats{}}
+}
+
+func (f *Func[K, V]) Call(key K) V {
+	if e, ok := f.cache[key]; ok {
+		f.stats.Hits++
+		f.order.MoveToFront(e)
+		return e.Value.(*entry[K, V]).value
+	}
+	f.stats.Misses++
+	value := f.fn(f.Call, key)
+	if e, ok := f.cache[key]; ok {
+		e.Value.(*entry[K, V]).value = value
+		f.order.MoveToFront(e)
+		return value
+	}
+	if f.capacity > 0 && f.order.Len() >= f.capacity {
+		oldest := f.order.Back()
+		f.order.Remove(oldest)
+		delete(f.cache, oldest.Value.(*entry[K, V]).key)
+		f.stats.Evictions++
+	}
+	f.cache[key] = f.order.PushFront(&entry[K, V]{key, value})
+	return value
+}
+
+func (f *Func[K, V]) Stats() Stats {
+	stats := f.stats
+	stats.Size = f.order.Len()
+	return stats
+}
+
+func (f *Func[K, V]) Reset() {
+	f.cache = map[K]*list.Element{}
+	f.order.Init()
+	f.stats = Stats{}
+}
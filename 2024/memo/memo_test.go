@@ -0,0 +1,72 @@
+package memo
+
+import "testing"
+
+func fibonacci(fib func(int) int, n int) int {
+	if n < 2 {
+		return n
+	}
+	return fib(n-1) + fib(n-2)
+}
+
+func TestCall(t *testing.T) {
+	f := New(fibonacci)
+	result := f.Call(90)
+	if result != 2880067194370816120 {
+		t.Errorf("Call(90) == %d, expected 2880067194370816120", result)
+	}
+	expected := Stats{Hits: 88, Misses: 91, Size: 91}
+	if f.Stats() != expected {
+		t.Errorf("Stats() == %v, expected %v", f.Stats(), expected)
+	}
+
+	f.Call(90)
+	expected.Hits++
+	if f.Stats() != expected {
+		t.Errorf("Stats() == %v, expected %v", f.Stats(), expected)
+	}
+
+	f.Reset()
+	if f.Stats() != (Stats{}) {
+		t.Errorf("Stats() == %v after Reset(), expected %v", f.Stats(), Stats{})
+	}
+}
+
+func TestLRU(t *testing.T) {
+	f := NewLRU(3, fibonacci)
+	result := f.Call(40)
+	if result != 102334155 {
+		t.Errorf("Call(40) == %d, expected 102334155", result)
+	}
+	stats := f.Stats()
+	if stats.Size != 3 || stats.Evictions != stats.Misses-3 {
+		t.Errorf("Stats() == %v, expected size 3 and %d evictions", stats, stats.Misses-3)
+	}
+
+	calls := 0
+	g := NewLRU(2, func(_ func(int) int, n int) int {
+		calls++
+		return n * n
+	})
+	for _, n := range []int{1, 2, 1, 3, 2, 1} {
+		g.Call(n)
+	}
+	expected := Stats{Hits: 1, Misses: 5, Evictions: 3, Size: 2}
+	if g.Stats() != expected || calls != 5 {
+		t.Errorf("Stats() == %v with %d calls, expected %v with 5 calls", g.Stats(), calls, expected)
+	}
+}
+
+func TestPair(t *testing.T) {
+	f := New(func(binomial func(Pair[int, int]) int, k Pair[int, int]) int {
+		n, r := k.First, k.Second
+		if r == 0 || r == n {
+			return 1
+		}
+		return binomial(Pair[int, int]{n - 1, r - 1}) + binomial(Pair[int, int]{n - 1, r})
+	})
+	result := f.Call(Pair[int, int]{60, 30})
+	if result != 118264581564861424 {
+		t.Errorf("Call({60, 30}) == %d, expected 118264581564861424", result)
+	}
+}
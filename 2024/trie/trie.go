@@ -0,0 +1,201 @@
+package trie
+
+import (
+	"iter"
+	"maps"
+	"slices"
+)
+
+type node[V any] struct {
+	children map[byte]int
+	value    V
+	terminal bool
+	depth    int
+}
+
+type Trie[V any] struct {
+	nodes []node[V]
+	size  int
+}
+
+func NewTrie[V any]() *Trie[V] {
+	return &Trie[V]{[]node[V]{{children: map[byte]int{}}}, 0}
+}
+
+func (t *Trie[V]) Insert(key string, value V) {
+	current := 0
+	for i := 0; i < len(key); i++ {
+		next, ok := t.nodes[current].children[key[i]]
+		if !ok {
+			next = len(t.nodes)
+			t.nodes = append(t.nodes, node[V]{children: map[byte]int{}, depth: i + 1})
+			t.nodes[current].children[key[i]] = next
+		}
+		current = next
+	}
+	if !t.nodes[current].terminal {
+		t.size++
+	}
+	t.nodes[current].value = value
+	t.nodes[current].terminal = true
+}
+
+func (t *Trie[V]) find(key string) (int, bool) {
+	current := 0
+	for i := 0; i < len(key); i++ {
+		next, ok := t.nodes[current].children[key[i]]
+		if !ok {
+			return 0, false
+		}
+		current = next
+	}
+	return current, true
+}
+
+func (t *Trie[V]) Get(key string) (V, bool) {
+	var zero V
+	n, ok := t.find(key)
+	if !ok || !t.nodes[n].terminal {
+		return zero, false
+	}
+	return t.nodes[n].value, true
+}
+
+func (t *Trie[V]) Len() int {
+	return t.size
+}
+
+// Prefixes yields the length and value of every key that is a prefix of s,
+// shortest first.
+func (t *Trie[V]) Prefixes(s string) iter.Seq2[int, V] {
+	return func(yield func(int, V) bool) {
+		current := 0
+		for i := 0; ; i++ {
+			if t.nodes[current].terminal && !yield(i, t.nodes[current].value) {
+				return
+			}
+			if i == len(s) {
+				return
+			}
+			next, ok := t.nodes[current].children[s[i]]
+			if !ok {
+				return
+			}
+			current = next
+		}
+	}
+}
+
+// WithPrefix yields every key starting with prefix in lexicographic order.
+func (t *Trie[V]) WithPrefix(prefix string) iter.Seq2[string, V] {
+	return func(yield func(string, V) bool) {
+		start, ok := t.find(prefix)
+		if !ok {
+			return
+		}
+		var walk func(int, []byte) bool
+		walk = func(n int, key []byte) bool {
+			if t.nodes[n].terminal && !yield(string(key), t.nodes[n].value) {
+				return false
+			}
+			for _, b := range slices.Sorted(maps.Keys(t.nodes[n].children)) {
+				if !walk(t.nodes[n].children[b], append(key, b)) {
+					return false
+				}
+			}
+			return true
+		}
+		walk(start, []byte(prefix))
+	}
+}
+
+func (t *Trie[V]) All() iter.Seq2[string, V] {
+	return t.WithPrefix("")
+}
+
+type Match[V any] struct {
+	Start, End int
+	Value      V
+}
+
+// Automaton is an Aho-Corasick automaton finding every occurrence of the
+// keys of a trie in a single pass over the input.
+type Automaton[V any] struct {
+	nodes  []node[V]
+	fail   []int
+	output []int
+}
+
+func (t *Trie[V]) Compile() *Automaton[V] {
+	nodes := make([]node[V], len(t.nodes))
+	for i, n := range t.nodes {
+		nodes[i] = n
+		nodes[i].children = maps.Clone(n.children)
+	}
+	a := &Automaton[V]{nodes, make([]int, len(nodes)), make([]int, len(nodes))}
+	a.output[0] = -1
+
+	queue := []int{}
+	for _, child := range nodes[0].children {
+		a.fail[child] = 0
+		a.output[child] = -1
+		queue = append(queue, child)
+	}
+	for len(queue) > 0 {
+		n := queue[0]
+		queue = queue[1:]
+		for b, child := range nodes[n].children {
+			f := a.fail[n]
+			for {
+				if next, ok := nodes[f].children[b]; ok {
+					f = next
+					break
+				}
+				if f == 0 {
+					break
+				}
+				f = a.fail[f]
+			}
+			a.fail[child] = f
+			if nodes[f].terminal {
+				a.output[child] = f
+			} else {
+				a.output[child] = a.output[f]
+			}
+			queue = append(queue, child)
+		}
+	}
+	return a
+}
+
+func (a *Automaton[V]) next(state int, b byte) int {
+	for {
+		if next, ok := a.nodes[state].children[b]; ok {
+			return next
+		}
+		if state == 0 {
+			return 0
+		}
+		state = a.fail[state]
+	}
+}
+
+// FindAll yields every, possibly overlapping, match in s ordered by end
+// position, longest first for matches ending at the same position.
+func (a *Automaton[V]) FindAll(s string) iter.Seq[Match[V]] {
+	return func(yield func(Match[V]) bool) {
+		state := 0
+		for i := 0; i < len(s); i++ {
+			state = a.next(state, s[i])
+			n := state
+			if !a.nodes[n].terminal {
+				n = a.output[n]
+			}
+			for ; n >= 0; n = a.output[n] {
+				if !yield(Match[V]{i + 1 - a.nodes[n].depth, i + 1, a.nodes[n].value}) {
+					return
+				}
+			}
+		}
+	}
+}
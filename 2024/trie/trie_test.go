@@ -0,0 +1,129 @@
+package trie
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+)
+
+func newTestTrie(keys ...string) *Trie[int] {
+	t := NewTrie[int]()
+	for i, key := range keys {
+		t.Insert(key, i)
+	}
+	return t
+}
+
+func TestTrie(t *testing.T) {
+	trie := newTestTrie("r", "wr", "b", "g", "bwu", "rb", "gb", "br", "r")
+	if trie.Len() != 8 {
+		t.Errorf("Len() == %d, expected 8", trie.Len())
+	}
+	cases := []struct {
+		key   string
+		value int
+		ok    bool
+	}{
+		{"r", 8, true},
+		{"bwu", 4, true},
+		{"bw", 0, false},
+		{"x", 0, false},
+		{"", 0, false},
+	}
+	for _, c := range cases {
+		value, ok := trie.Get(c.key)
+		if value != c.value || ok != c.ok {
+			t.Errorf("Get(%q) == %d, %t, expected %d, %t", c.key, value, ok, c.value, c.ok)
+		}
+	}
+}
+
+func TestPrefixes(t *testing.T) {
+	trie := newTestTrie("b", "br", "bwu", "brw", "w")
+	cases := []struct {
+		s        string
+		expected []string
+	}{
+		{"brwrr", []string{"1:0", "2:1", "3:3"}},
+		{"bwurrg", []string{"1:0", "3:2"}},
+		{"rb", []string{}},
+		{"", []string{}},
+	}
+	for _, c := range cases {
+		result := []string{}
+		for n, v := range trie.Prefixes(c.s) {
+			result = append(result, fmt.Sprintf("%d:%d", n, v))
+		}
+		if !slices.Equal(result, c.expected) {
+			t.Errorf("Prefixes(%q) == %v, expected %v", c.s, result, c.expected)
+		}
+	}
+}
+
+func TestWithPrefix(t *testing.T) {
+	trie := newTestTrie("car", "cat", "ca", "dog", "cart")
+	cases := []struct {
+		prefix   string
+		expected []string
+	}{
+		{"ca", []string{"ca", "car", "cart", "cat"}},
+		{"car", []string{"car", "cart"}},
+		{"d", []string{"dog"}},
+		{"x", []string{}},
+		{"", []string{"ca", "car", "cart", "cat", "dog"}},
+	}
+	for _, c := range cases {
+		result := []string{}
+		for key := range trie.WithPrefix(c.prefix) {
+			result = append(result, key)
+		}
+		if !slices.Equal(result, c.expected) {
+			t.Errorf("WithPrefix(%q) == %v, expected %v", c.prefix, result, c.expected)
+		}
+	}
+}
+
+func TestFindAll(t *testing.T) {
+	cases := []struct {
+		keys     []string
+		s        string
+		expected []Match[int]
+	}{
+		{
+			[]string{"he", "she", "his", "hers"},
+			"ushers",
+			[]Match[int]{{1, 4, 1}, {2, 4, 0}, {2, 6, 3}},
+		},
+		{
+			[]string{"one", "two", "eight", "1"},
+			"xtwone1eightwo",
+			[]Match[int]{{1, 4, 1}, {3, 6, 0}, {6, 7, 3}, {7, 12, 2}, {11, 14, 1}},
+		},
+		{
+			[]string{"a", "aa", "aaa"},
+			"aaa",
+			[]Match[int]{{0, 1, 0}, {0, 2, 1}, {1, 2, 0}, {0, 3, 2}, {1, 3, 1}, {2, 3, 0}},
+		},
+		{
+			[]string{"abc"},
+			"ababd",
+			[]Match[int]{},
+		},
+	}
+	for _, c := range cases {
+		a := newTestTrie(c.keys...).Compile()
+		result := []Match[int]{}
+		for m := range a.FindAll(c.s) {
+			result = append(result, m)
+		}
+		slices.SortStableFunc(result, func(x, y Match[int]) int {
+			if x.End != y.End {
+				return x.End - y.End
+			}
+			return x.Start - y.Start
+		})
+		if !slices.Equal(result, c.expected) {
+			t.Errorf("FindAll(%v, %q) == %v, expected %v", c.keys, c.s, result, c.expected)
+		}
+	}
+}